@@ -1,29 +1,123 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
 	"github.com/watzon/0x45-cli/internal/handlers"
+	"github.com/watzon/0x45-cli/internal/logging"
 	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+	"golang.org/x/term"
 )
 
 var cfgFile string
 
 func main() {
-	rootCmd := &cobra.Command{
+	// Help text is styled while the command tree is built, before flags are
+	// parsed, so --no-color has to be picked out of the arguments up front.
+	if !colorEnabled(os.Args[1:], term.IsTerminal(int(os.Stdout.Fd()))) {
+		theme.DisableColor()
+	}
+
+	rootCmd, closeOutput := newRootCmd()
+	cobra.OnInitialize(initConfig)
+
+	// Ctrl-C cancels in-flight requests. Once it has, the default handling
+	// is restored so a second Ctrl-C kills the process outright.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	client.SetContext(ctx)
+
+	err := rootCmd.ExecuteContext(ctx)
+	if closeErr := closeOutput(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing output: %w", closeErr)
+	}
+	if err != nil {
+		msg := err.Error()
+		if errors.Is(err, context.Canceled) {
+			msg = "cancelled"
+		}
+		fmt.Fprintln(os.Stderr, theme.FormatError(msg))
+		os.Exit(exitCode(err))
+	}
+}
+
+// newRootCmd builds the command tree. closeOutput closes the --output file,
+// if one was opened, once the command has run.
+func newRootCmd() (rootCmd *cobra.Command, closeOutput func() error) {
+	// outFile is the --output file, if any.
+	var outFile *os.File
+
+	rootCmd = &cobra.Command{
 		Use:   "0x45",
 		Short: theme.Title.Render("A CLI client for 0x45.st"),
 		Long: theme.InfoBox.Render(`0x45 is a command line interface for 0x45.st, a file and URL sharing service.
 It allows you to upload files, shorten URLs, and manage your content.`),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return validateAPIKey()
+			file, err := setup(cmd)
+			if file != nil {
+				outFile = file
+			}
+			return err
 		},
 	}
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.0x45.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/0x45/config.yaml)")
+	rootCmd.PersistentFlags().String("api-key", "", "API key, or a file:/path or env:NAME reference to one")
+	cobra.CheckErr(viper.BindPFlag("api_key", rootCmd.PersistentFlags().Lookup("api-key")))
+	rootCmd.PersistentFlags().Bool("api-key-stdin", false, "Read the API key from the first line of stdin, or a prompt without echo")
+	rootCmd.MarkFlagsMutuallyExclusive("api-key", "api-key-stdin")
+	rootCmd.PersistentFlags().String("server", "", "Server URL to use instead of the configured api_url")
+	cobra.CheckErr(viper.BindPFlag("api_url", rootCmd.PersistentFlags().Lookup("server")))
+	rootCmd.PersistentFlags().Bool("insecure-allow-http", false, "Allow sending the API key to a server over plain HTTP")
+	cobra.CheckErr(viper.BindPFlag("insecure_allow_http", rootCmd.PersistentFlags().Lookup("insecure-allow-http")))
+	rootCmd.PersistentFlags().Bool("json", false, "Output raw JSON responses")
+	cobra.CheckErr(viper.BindPFlag("json", rootCmd.PersistentFlags().Lookup("json")))
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Print only essential output, such as the URL of an upload")
+	cobra.CheckErr(viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet")))
+	rootCmd.MarkFlagsMutuallyExclusive("json", "quiet")
+	rootCmd.PersistentFlags().Bool("json-pretty", false, "Indent JSON output even when it isn't going to a terminal")
+	cobra.CheckErr(viper.BindPFlag("json_pretty", rootCmd.PersistentFlags().Lookup("json-pretty")))
+	rootCmd.PersistentFlags().Bool("json-compact", false, "Print JSON output on one line even in a terminal")
+	cobra.CheckErr(viper.BindPFlag("json_compact", rootCmd.PersistentFlags().Lookup("json-compact")))
+	rootCmd.MarkFlagsMutuallyExclusive("json-pretty", "json-compact")
+	rootCmd.PersistentFlags().String("date-format", "", "Go time layout for displayed dates, or \"relative\" (e.g. \"3 days ago\")")
+	cobra.CheckErr(viper.BindPFlag("date_format", rootCmd.PersistentFlags().Lookup("date-format")))
+	rootCmd.PersistentFlags().Bool("relative", false, "Show dates relative to now (same as --date-format relative)")
+	cobra.CheckErr(viper.BindPFlag("relative", rootCmd.PersistentFlags().Lookup("relative")))
+	rootCmd.MarkFlagsMutuallyExclusive("date-format", "relative")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Write output to this file instead of stdout (- for stdout)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log HTTP request and response details to stderr (same as --log-level debug)")
+	cobra.CheckErr(viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose")))
+	rootCmd.PersistentFlags().String("log-level", "", "Lowest level of log messages to show on stderr: debug, info, warn or error (default warn)")
+	cobra.CheckErr(viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log-level")))
+	rootCmd.PersistentFlags().Bool("no-color", false, "Disable colored and styled output (also set by NO_COLOR)")
+	rootCmd.PersistentFlags().String("profile", "", "Use the named profile from the config file")
+	rootCmd.PersistentFlags().Bool("no-proxy", false, "Connect directly, ignoring http_proxy and the proxy environment variables")
+	cobra.CheckErr(viper.BindPFlag("disable_proxy", rootCmd.PersistentFlags().Lookup("no-proxy")))
+	rootCmd.PersistentFlags().Bool("follow-redirects", true, "Follow HTTP redirects; with false, the redirect itself is shown")
+	cobra.CheckErr(viper.BindPFlag("follow_redirects", rootCmd.PersistentFlags().Lookup("follow-redirects")))
+	rootCmd.PersistentFlags().Bool("no-follow", false, "Don't follow HTTP redirects (same as --follow-redirects=false)")
+	rootCmd.MarkFlagsMutuallyExclusive("follow-redirects", "no-follow")
+	rootCmd.PersistentFlags().StringArrayP("header", "H", nil, "Add a header to every request, as \"Key: Value\" (repeatable)")
+	cobra.CheckErr(viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile")))
 
 	rootCmd.AddCommand(
 		handlers.NewConfigCmd(),
@@ -31,13 +125,149 @@ It allows you to upload files, shorten URLs, and manage your content.`),
 		handlers.NewShortenCmd(),
 		handlers.NewListCmd(),
 		handlers.NewDeleteCmd(),
+		handlers.NewStatsCmd(),
+		handlers.NewRenewCmd(),
+		handlers.NewQRCmd(),
+		handlers.NewGetCmd(),
+		handlers.NewOpenCmd(),
+		handlers.NewBackupCmd(),
+		handlers.NewSearchCmd(),
+		handlers.NewBrowseCmd(),
+		handlers.NewPruneCmd(),
+		handlers.NewKeyCmd(),
 	)
 
-	cobra.OnInitialize(initConfig)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &handlers.UsageError{Err: err}
+	})
+	markArgErrors(rootCmd)
+
+	return rootCmd, func() error {
+		if outFile == nil {
+			return nil
+		}
+		return outFile.Close()
+	}
+}
+
+// setup applies the global flags before any command runs, returning the
+// --output file if one was opened. Commands that never talk to the server,
+// such as config, skip the checks that only matter for requests, so a
+// broken setting can still be fixed with them.
+func setup(cmd *cobra.Command) (*os.File, error) {
+	usesServer := handlers.UsesServer(cmd)
+
+	if server, _ := cmd.Flags().GetString("server"); server != "" {
+		if err := validateServerURL(server); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyProfile(cmd.Root().PersistentFlags()); err != nil {
+		return nil, err
+	}
+	if err := applyHeaders(cmd.Root().PersistentFlags(), usesServer); err != nil {
+		return nil, err
+	}
+	file, err := openOutput(cmd.Root().PersistentFlags())
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		cmd.Root().SetOut(file)
+		theme.DisableColor()
+	}
+	if noFollow, _ := cmd.Flags().GetBool("no-follow"); noFollow {
+		viper.Set("follow_redirects", false)
+	}
+	if keyStdin, _ := cmd.Flags().GetBool("api-key-stdin"); keyStdin {
+		key, err := handlers.ReadSecret(cmd, "API key: ")
+		if err != nil {
+			return file, fmt.Errorf("error reading API key: %w", err)
+		}
+		viper.Set("api_key", key)
+	}
+	if !usesServer {
+		return file, nil
+	}
+	if handlers.RequiresAPIKey(cmd) {
+		if err := validateAPIKey(); err != nil {
+			return file, err
+		}
+	}
+	if err := checkInsecureHTTP(); err != nil {
+		return file, err
+	}
+	// The client is first built during package init, before the config
+	// file has been read.
+	return file, client.Initialize()
+}
+
+// Exit codes, documented in the README so scripts can rely on them.
+const (
+	exitError    = 1
+	exitUsage    = 2
+	exitAuth     = 3
+	exitNotFound = 4
+	exitNetwork  = 5
+	// exitCancelled follows the shell convention of 128+SIGINT.
+	exitCancelled = 130
+)
+
+// exitCode maps an error to the exit code for its class.
+func exitCode(err error) int {
+	var usageErr *handlers.UsageError
+	var keyErr *authError
+	var apiErr *api.APIError
+	var urlErr *url.Error
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitCancelled
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, api.ErrNotFound):
+		return exitNotFound
+	case errors.As(err, &keyErr):
+		return exitAuth
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden):
+		return exitAuth
+	case errors.Is(err, api.ErrTimeout), errors.As(err, &urlErr), errors.As(err, &netErr):
+		return exitNetwork
+	}
+	return exitError
+}
+
+// markArgErrors wraps the argument validators of cmd and its subcommands so
+// that wrong argument counts are reported as usage errors.
+func markArgErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return &handlers.UsageError{Err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markArgErrors(sub)
+	}
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(theme.FormatError(err.Error()))
-		os.Exit(1)
+// bindEnv makes every setting overridable with an OX45_ environment
+// variable named after it, e.g. OX45_API_KEY for api_key, so the CLI can run
+// from the environment alone. Known keys are bound explicitly on top of
+// AutomaticEnv so values set only in the environment are listed by config
+// list and checked by config validate. Profiles and theme colors are nested
+// and can only come from the config file.
+func bindEnv() {
+	viper.SetEnvPrefix("OX45")
+	viper.AutomaticEnv()
+	for _, key := range handlers.ConfigKeys {
+		if key == "profiles" || key == "theme" {
+			continue
+		}
+		cobra.CheckErr(viper.BindEnv(key))
 	}
 }
 
@@ -45,32 +275,222 @@ func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
-		home, err := os.UserHomeDir()
+		path, err := handlers.DefaultConfigFile()
 		cobra.CheckErr(err)
 
-		viper.AddConfigPath(home)
+		if from, to, err := handlers.MigrateLegacyConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, theme.FormatWarning(fmt.Sprintf("Could not migrate config file: %v", err)))
+		} else if to != "" {
+			fmt.Fprintln(os.Stderr, theme.FormatSuccess(fmt.Sprintf("Copied config file %s to %s", from, to)))
+		}
+
+		viper.AddConfigPath(filepath.Dir(path))
 		viper.SetConfigType("yaml")
-		viper.SetConfigName(".0x45")
+		viper.SetConfigName("config")
 	}
 
-	viper.SetEnvPrefix("OX45")
-	viper.AutomaticEnv()
+	bindEnv()
 
 	// Set default values
 	viper.SetDefault("api_url", "https://0x45.st")
+	viper.SetDefault("request_timeout", "30s")
+	viper.SetDefault("default_stdin_filename", "paste.txt")
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			fmt.Println(theme.FormatError(fmt.Sprintf("Error reading config file: %v", err)))
+	configErr := viper.ReadInConfig()
+	if err := applyLogLevel(); err != nil {
+		logging.Warnf("%v, using warn", err)
+	}
+	if configErr != nil {
+		if _, ok := configErr.(viper.ConfigFileNotFoundError); !ok {
+			logging.Errorf("Error reading config file: %v", configErr)
 		}
 	} else {
-		fmt.Println(theme.FormatSuccess(fmt.Sprintf("Using config file: %s", viper.ConfigFileUsed())))
+		logging.Infof("Using config file: %s", viper.ConfigFileUsed())
+	}
+
+	for _, err := range theme.ApplyColors(viper.GetStringMapString("theme")) {
+		logging.Warnf("%v, using the default", err)
 	}
 }
 
+// applyLogLevel sets the log level from log_level, or to debug with
+// --verbose. An invalid level leaves the default of warn.
+func applyLogLevel() error {
+	if viper.GetBool("verbose") {
+		logging.SetLevel(logging.Debug)
+		return nil
+	}
+	name := viper.GetString("log_level")
+	if name == "" {
+		logging.SetLevel(logging.Warn)
+		return nil
+	}
+	level, err := logging.ParseLevel(name)
+	logging.SetLevel(level)
+	return err
+}
+
+// noColorRequested reports whether styling should be disabled, either with
+// --no-color among args or through the NO_COLOR environment variable
+// (https://no-color.org).
+func noColorRequested(args []string) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--no-color" || arg == "--no-color=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// colorEnabled decides whether output is styled. An explicit --no-color or
+// NO_COLOR always wins; otherwise color is used only when stdout is a
+// terminal, unless CLICOLOR_FORCE asks for it regardless.
+func colorEnabled(args []string, stdoutIsTerminal bool) bool {
+	if noColorRequested(args) {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return stdoutIsTerminal
+}
+
+// settingFlags maps config keys to the flags that override them, where the
+// flag isn't simply the key with dashes.
+var settingFlags = map[string]string{
+	"api_url": "server",
+}
+
+// validateServerURL checks that a --server value is an absolute http(s) URL.
+func validateServerURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &handlers.UsageError{Err: fmt.Errorf("invalid server URL: %s (expected e.g. https://0x45.st)", raw)}
+	}
+	return nil
+}
+
+// checkInsecureHTTP refuses to send the API key to a server over plain HTTP,
+// where anyone on the network path could read it, unless
+// --insecure-allow-http is given. Loopback addresses are exempt for local
+// development, and anonymous requests have no key to protect.
+func checkInsecureHTTP() error {
+	if key, err := client.APIKey(); err != nil || key == "" {
+		return nil
+	}
+	u, err := url.Parse(viper.GetString("api_url"))
+	if err != nil || u.Scheme != "http" || isLoopbackHost(u.Hostname()) {
+		return nil
+	}
+	if !viper.GetBool("insecure_allow_http") {
+		return &handlers.UsageError{Err: fmt.Errorf("refusing to send the API key over plain HTTP to %s: use https, or pass --insecure-allow-http", u.Host)}
+	}
+	logging.Warnf("Sending the API key over plain HTTP to %s, where anyone on the network can read it", u.Host)
+	return nil
+}
+
+// isLoopbackHost reports whether host is this machine.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// openOutput creates the --output file, and any missing parent directories,
+// for command output to be written to. It returns nil when output goes to
+// stdout, with no flag or "-". Commands with their own --output, such as get,
+// shadow this one.
+func openOutput(flags *pflag.FlagSet) (*os.File, error) {
+	path, err := flags.GetString("output")
+	if err != nil || path == "" || path == "-" {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return nil, &handlers.UsageError{Err: fmt.Errorf("--output %s is a directory", path)}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating output directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating output file: %w", err)
+	}
+	return file, nil
+}
+
+// applyHeaders validates the custom request headers, when validate is set.
+// Headers given with --header replace any configured under "headers". The
+// flag isn't bound to viper because viper would split its values on commas.
+func applyHeaders(flags *pflag.FlagSet, validate bool) error {
+	if flags.Changed("header") {
+		headers, err := flags.GetStringArray("header")
+		if err != nil {
+			return err
+		}
+		viper.Set("headers", headers)
+	}
+	if !validate {
+		return nil
+	}
+
+	if _, err := client.ParseHeaders(client.ConfiguredHeaders()); err != nil {
+		return &handlers.UsageError{Err: err}
+	}
+	return nil
+}
+
+// applyProfile overlays the settings of the selected profile, if any, on top
+// of the top-level config. Values given explicitly through a flag or an
+// OX45_ environment variable still take precedence.
+func applyProfile(flags *pflag.FlagSet) error {
+	name := viper.GetString("profile")
+	if name == "" {
+		return nil
+	}
+
+	key := "profiles." + name
+	if !viper.IsSet(key) {
+		return &handlers.UsageError{Err: fmt.Errorf("unknown profile: %s", name)}
+	}
+
+	for setting, value := range viper.GetStringMap(key) {
+		flagName, ok := settingFlags[setting]
+		if !ok {
+			flagName = strings.ReplaceAll(setting, "_", "-")
+		}
+		if flag := flags.Lookup(flagName); flag != nil && flag.Changed {
+			continue
+		}
+		if _, ok := os.LookupEnv("OX45_" + strings.ToUpper(setting)); ok {
+			continue
+		}
+		viper.Set(setting, value)
+	}
+	return nil
+}
+
+// authError reports a missing or unusable API key before any request is made.
+type authError struct {
+	msg string
+}
+
+func (e *authError) Error() string { return e.msg }
+
 func validateAPIKey() error {
-	if viper.GetString("api_key") == "" {
-		return fmt.Errorf("%s", theme.RenderErrorBox("API key not set. Run '0x45 config set api_key YOUR_API_KEY' to set it"))
+	apiKey, err := client.APIKey()
+	if err != nil {
+		return &authError{msg: theme.RenderErrorBox(fmt.Sprintf("Could not load API key: %v", err))}
+	}
+	if apiKey == "" {
+		return &authError{msg: theme.RenderErrorBox("API key not set. Run '0x45 config set api_key YOUR_API_KEY' to set it")}
 	}
 	return nil
 }
@@ -2,14 +2,27 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
 	"github.com/watzon/0x45-cli/internal/handlers"
+	"github.com/watzon/0x45-cli/internal/logging"
+	api "github.com/watzon/0x45-cli/pkg/client"
 )
 
 // Helper functions for testing
@@ -18,6 +31,7 @@ func setupTestEnv(t *testing.T) (func(), string) {
 	tmpDir := t.TempDir()
 	oldHome := os.Getenv("HOME")
 	os.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 
 	// Reset viper
 	viper.Reset()
@@ -66,6 +80,101 @@ func TestInitConfig(t *testing.T) {
 	}
 }
 
+func TestInitConfigXDG(t *testing.T) {
+	cleanup, _ := setupTestEnv(t)
+	defer cleanup()
+
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if err := os.MkdirAll(filepath.Join(xdg, "0x45"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(xdg, "0x45", "config.yaml")
+	if err := os.WriteFile(configFile, []byte("api_key: xdg-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgFile = ""
+	initConfig()
+
+	if key := viper.GetString("api_key"); key != "xdg-key" {
+		t.Errorf("Expected API key from $XDG_CONFIG_HOME, got %q", key)
+	}
+	if used := viper.ConfigFileUsed(); used != configFile {
+		t.Errorf("Expected config file %q, got %q", configFile, used)
+	}
+}
+
+func TestInitConfigEnvOnly(t *testing.T) {
+	cleanup, _ := setupTestEnv(t)
+	defer cleanup()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var auth, trace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, trace = r.Header.Get("Authorization"), r.Header.Get("X-Trace-Id")
+		_ = json.NewEncoder(w).Encode(api.ShortenResponse{Success: true, URL: "https://0x45.st/abc"})
+	}))
+	defer server.Close()
+
+	t.Setenv("OX45_API_KEY", "env-key")
+	t.Setenv("OX45_API_URL", server.URL)
+	t.Setenv("OX45_COPY_ON_UPLOAD", "true")
+	t.Setenv("OX45_REQUEST_TIMEOUT", "5s")
+	t.Setenv("OX45_HEADERS", "X-Trace-Id: a, b\nX-Env: 1")
+
+	cfgFile = ""
+	initConfig()
+
+	if used := viper.ConfigFileUsed(); used != "" {
+		t.Errorf("Expected no config file, got %q", used)
+	}
+	if !viper.GetBool("copy_on_upload") || viper.GetDuration("request_timeout") != 5*time.Second {
+		t.Errorf("Expected settings from the environment, got copy_on_upload=%v request_timeout=%v",
+			viper.Get("copy_on_upload"), viper.Get("request_timeout"))
+	}
+	if !slices.Contains(viper.AllKeys(), "copy_on_upload") {
+		t.Errorf("Expected environment-only settings to be listed, got %v", viper.AllKeys())
+	}
+	if err := validateAPIKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ShortenURL("https://example.com", false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer env-key" || trace != "a, b" {
+		t.Errorf("Expected the key and headers from the environment, got %q and %q", auth, trace)
+	}
+}
+
+func TestInitConfigMigratesLegacy(t *testing.T) {
+	cleanup, tmpDir := setupTestEnv(t)
+	defer cleanup()
+
+	legacy := filepath.Join(tmpDir, ".0x45.yaml")
+	if err := os.WriteFile(legacy, []byte("api_key: legacy-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgFile = ""
+	initConfig()
+
+	if key := viper.GetString("api_key"); key != "legacy-key" {
+		t.Errorf("Expected API key from the migrated config, got %q", key)
+	}
+	configFile := filepath.Join(tmpDir, ".config", "0x45", "config.yaml")
+	if used := viper.ConfigFileUsed(); used != configFile {
+		t.Errorf("Expected config file %q, got %q", configFile, used)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("Expected legacy config to be kept: %v", err)
+	}
+}
+
 func TestValidateAPIKey(t *testing.T) {
 	cleanup, _ := setupTestEnv(t)
 	defer cleanup()
@@ -81,6 +190,18 @@ func TestValidateAPIKey(t *testing.T) {
 	if err := validateAPIKey(); err != nil {
 		t.Errorf("Unexpected error when API key is set: %v", err)
 	}
+
+	// Test with an environment variable reference
+	t.Setenv("OX45_TEST_KEY", "env-key")
+	viper.Set("api_key", "env:OX45_TEST_KEY")
+	if err := validateAPIKey(); err != nil {
+		t.Errorf("Unexpected error when API key references a set variable: %v", err)
+	}
+
+	viper.Set("api_key", "env:OX45_TEST_MISSING")
+	if err := validateAPIKey(); err == nil {
+		t.Error("Expected error when API key references an unset variable")
+	}
 }
 
 func TestCommandStructure(t *testing.T) {
@@ -91,6 +212,16 @@ func TestCommandStructure(t *testing.T) {
 		handlers.NewShortenCmd(),
 		handlers.NewListCmd(),
 		handlers.NewDeleteCmd(),
+		handlers.NewStatsCmd(),
+		handlers.NewRenewCmd(),
+		handlers.NewQRCmd(),
+		handlers.NewGetCmd(),
+		handlers.NewOpenCmd(),
+		handlers.NewBackupCmd(),
+		handlers.NewSearchCmd(),
+		handlers.NewBrowseCmd(),
+		handlers.NewPruneCmd(),
+		handlers.NewKeyCmd(),
 	)
 
 	// Test root command
@@ -105,6 +236,16 @@ func TestCommandStructure(t *testing.T) {
 		"shorten": true,
 		"list":    true,
 		"delete":  true,
+		"stats":   true,
+		"renew":   true,
+		"qr":      true,
+		"get":     true,
+		"open":    true,
+		"backup":  true,
+		"search":  true,
+		"browse":  true,
+		"prune":   true,
+		"key":     true,
 	}
 
 	for _, cmd := range rootCmd.Commands() {
@@ -151,6 +292,60 @@ func TestConfigCommand(t *testing.T) {
 	if key := viper.GetString("api_key"); key != "test-key" {
 		t.Errorf("Expected API key to be test-key, got %s", key)
 	}
+
+	// With --stdin the value is read from stdin and not echoed back.
+	cmd = handlers.NewConfigCmd()
+	b.Reset()
+	cmd.SetOut(b)
+	cmd.SetIn(strings.NewReader("piped-key\n"))
+	cmd.SetArgs([]string{"set", "api_key", "--stdin"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error executing config command: %v", err)
+	}
+	if out := b.String(); strings.Contains(out, "piped-key") || !strings.Contains(out, "Config value 'api_key' set") {
+		t.Errorf("Expected a success message without the key, got: %s", out)
+	}
+	if key := viper.GetString("api_key"); key != "piped-key" {
+		t.Errorf("Expected API key to be piped-key, got %s", key)
+	}
+
+	// --type stores booleans and numbers unquoted.
+	for _, args := range [][]string{
+		{"set", "copy_on_upload", "true", "--type", "bool"},
+		{"set", "retries", "3", "--type", "int"},
+		{"set", "default_expiry", "7d"},
+	} {
+		cmd = handlers.NewConfigCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Unexpected error setting %v: %v", args, err)
+		}
+	}
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"copy_on_upload: true\n", "retries: 3\n", "default_expiry: 7d\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in the config file, got:\n%s", want, data)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"set", "copy_on_upload", "yes please", "--type", "bool"},
+		{"set", "retries", "3.5", "--type", "int"},
+		{"set", "retries", "3", "--type", "float"},
+	} {
+		cmd = handlers.NewConfigCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		var usageErr *handlers.UsageError
+		if err := cmd.Execute(); !errors.As(err, &usageErr) {
+			t.Errorf("Expected a usage error for %v, got %v", args, err)
+		}
+	}
 }
 
 func TestUploadCommand(t *testing.T) {
@@ -210,3 +405,424 @@ func TestListCommand(t *testing.T) {
 	cmd.SetArgs([]string{"urls"})
 	_ = cmd.Execute()
 }
+
+func TestApplyProfile(t *testing.T) {
+	cleanup, tmpDir := setupTestEnv(t)
+	defer cleanup()
+
+	cfgFile = filepath.Join(tmpDir, ".0x45.yaml")
+	defer func() { cfgFile = "" }()
+	content := []byte("api_key: default-key\nprofiles:\n  work:\n    api_url: https://paste.example.com\n    api_key: work-key\n")
+	if err := os.WriteFile(cfgFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	initConfig()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("api-key", "", "")
+
+	// Without a profile the top-level settings are used.
+	if err := applyProfile(flags); err != nil {
+		t.Fatal(err)
+	}
+	if key := viper.GetString("api_key"); key != "default-key" {
+		t.Errorf("Expected default-key without a profile, got %s", key)
+	}
+
+	viper.Set("profile", "missing")
+	if err := applyProfile(flags); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+
+	viper.Set("profile", "work")
+	if err := applyProfile(flags); err != nil {
+		t.Fatal(err)
+	}
+	if key := viper.GetString("api_key"); key != "work-key" {
+		t.Errorf("Expected profile API key, got %s", key)
+	}
+	if url := viper.GetString("api_url"); url != "https://paste.example.com" {
+		t.Errorf("Expected profile API URL, got %s", url)
+	}
+
+	// An explicit flag wins over the profile.
+	viper.Reset()
+	initConfig()
+	flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("api-key", "", "")
+	_ = flags.Set("api-key", "flag-key")
+	if err := viper.BindPFlag("api_key", flags.Lookup("api-key")); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("profile", "work")
+	if err := applyProfile(flags); err != nil {
+		t.Fatal(err)
+	}
+	if key := viper.GetString("api_key"); key != "flag-key" {
+		t.Errorf("Expected --api-key to override the profile, got %s", key)
+	}
+	if url := viper.GetString("api_url"); url != "https://paste.example.com" {
+		t.Errorf("Expected profile API URL, got %s", url)
+	}
+}
+
+func TestApplyHeaders(t *testing.T) {
+	cleanup, _ := setupTestEnv(t)
+	defer cleanup()
+
+	viper.Set("headers", []string{"X-From-Config: yes"})
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringArrayP("header", "H", nil, "")
+	if err := applyHeaders(flags, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := viper.GetStringSlice("headers"); len(got) != 1 || got[0] != "X-From-Config: yes" {
+		t.Errorf("Expected configured headers without --header, got %v", got)
+	}
+
+	_ = flags.Parse([]string{"-H", "Accept: text/plain, */*", "-H", "X-Trace: 1"})
+	if err := applyHeaders(flags, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := viper.GetStringSlice("headers"); len(got) != 2 || got[0] != "Accept: text/plain, */*" {
+		t.Errorf("Expected --header to replace configured headers, got %v", got)
+	}
+
+	_ = flags.Set("header", "not a header")
+	var usageErr *handlers.UsageError
+	if err := applyHeaders(flags, true); !errors.As(err, &usageErr) {
+		t.Errorf("Expected usage error for malformed header, got %v", err)
+	}
+}
+
+func TestOpenOutput(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringP("output", "o", "", "")
+
+	if file, err := openOutput(flags); file != nil || err != nil {
+		t.Errorf("Expected stdout without --output, got %v, %v", file, err)
+	}
+	_ = flags.Set("output", "-")
+	if file, err := openOutput(flags); file != nil || err != nil {
+		t.Errorf("Expected stdout for -, got %v, %v", file, err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "exports", "2024", "pastes.json")
+	_ = flags.Set("output", path)
+	file, err := openOutput(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(file, `{"success":true}`)
+	file.Close()
+	if data, err := os.ReadFile(path); err != nil || string(data) != "{\"success\":true}\n" {
+		t.Errorf("Expected output in %s, got %q, %v", path, data, err)
+	}
+
+	_ = flags.Set("output", dir)
+	var usageErr *handlers.UsageError
+	if _, err := openOutput(flags); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for a directory, got %v", err)
+	}
+}
+
+func TestApplyLogLevel(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	defer logging.SetLevel(logging.Warn)
+
+	if err := applyLogLevel(); err != nil || logging.Enabled(logging.Info) || !logging.Enabled(logging.Warn) {
+		t.Errorf("Expected warn by default, got %v", err)
+	}
+
+	viper.Set("log_level", "info")
+	if err := applyLogLevel(); err != nil || !logging.Enabled(logging.Info) || logging.Enabled(logging.Debug) {
+		t.Errorf("Expected info from log_level, got %v", err)
+	}
+
+	viper.Set("verbose", true)
+	if err := applyLogLevel(); err != nil || !logging.Enabled(logging.Debug) {
+		t.Errorf("Expected --verbose to mean debug, got %v", err)
+	}
+
+	viper.Set("verbose", false)
+	viper.Set("log_level", "chatty")
+	if err := applyLogLevel(); err == nil || logging.Enabled(logging.Info) {
+		t.Errorf("Expected an error and warn for an invalid level, got %v", err)
+	}
+}
+
+func TestCheckInsecureHTTP(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("api_key", "test-key")
+
+	tests := []struct {
+		apiURL  string
+		allow   bool
+		wantErr bool
+	}{
+		{apiURL: "https://0x45.st"},
+		{apiURL: "http://localhost:3000"},
+		{apiURL: "http://127.0.0.1:3000"},
+		{apiURL: "http://[::1]:3000"},
+		{apiURL: "http://paste.localhost"},
+		{apiURL: "http://0x45.st", wantErr: true},
+		{apiURL: "http://192.168.1.10:3000", wantErr: true},
+		{apiURL: "http://0x45.st", allow: true},
+	}
+	for _, tt := range tests {
+		viper.Set("api_url", tt.apiURL)
+		viper.Set("insecure_allow_http", tt.allow)
+		err := checkInsecureHTTP()
+		var usageErr *handlers.UsageError
+		if tt.wantErr && !errors.As(err, &usageErr) {
+			t.Errorf("%s: expected a usage error, got %v", tt.apiURL, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s (allow %v): unexpected error: %v", tt.apiURL, tt.allow, err)
+		}
+	}
+
+	viper.Set("api_key", "")
+	viper.Set("api_url", "http://0x45.st")
+	viper.Set("insecure_allow_http", false)
+	if err := checkInsecureHTTP(); err != nil {
+		t.Errorf("Expected anonymous requests over HTTP to be allowed, got %v", err)
+	}
+}
+
+func TestNoColorRequested(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"list", "pastes"}, want: false},
+		{args: []string{"list", "pastes", "--no-color"}, want: true},
+		{args: []string{"--no-color=true", "upload", "file.txt"}, want: true},
+		{args: []string{"--no-color=false", "upload", "file.txt"}, want: false},
+		{args: []string{"upload", "--", "--no-color"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := noColorRequested(tt.args); got != tt.want {
+			t.Errorf("noColorRequested(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !noColorRequested(nil) {
+		t.Error("Expected NO_COLOR to disable color")
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	if !colorEnabled(nil, true) {
+		t.Error("Expected color on a terminal")
+	}
+	if colorEnabled(nil, false) {
+		t.Error("Expected no color when stdout is not a terminal")
+	}
+	if colorEnabled([]string{"--no-color"}, true) {
+		t.Error("Expected --no-color to disable color on a terminal")
+	}
+
+	t.Setenv("CLICOLOR_FORCE", "1")
+	if !colorEnabled(nil, false) {
+		t.Error("Expected CLICOLOR_FORCE to keep color when piped")
+	}
+	if colorEnabled([]string{"--no-color"}, false) {
+		t.Error("Expected --no-color to win over CLICOLOR_FORCE")
+	}
+}
+
+func TestValidateServerURL(t *testing.T) {
+	valid := []string{"https://0x45.st", "http://localhost:3000", "https://staging.example.com/api"}
+	for _, raw := range valid {
+		if err := validateServerURL(raw); err != nil {
+			t.Errorf("validateServerURL(%q): unexpected error: %v", raw, err)
+		}
+	}
+
+	invalid := []string{"0x45.st", "ftp://0x45.st", "https://", "htps://0x45.st", "://bad"}
+	for _, raw := range invalid {
+		if err := validateServerURL(raw); err == nil {
+			t.Errorf("validateServerURL(%q): expected error", raw)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	cleanup, _ := setupTestEnv(t)
+	defer cleanup()
+	viper.Set("api_key", "")
+
+	apiErr := func(status int) error {
+		return fmt.Errorf("error deleting content: %w", &api.APIError{StatusCode: status})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "generic", err: errors.New("boom"), want: exitError},
+		{name: "server error", err: apiErr(http.StatusInternalServerError), want: exitError},
+		{name: "usage", err: &handlers.UsageError{Err: errors.New("invalid order")}, want: exitUsage},
+		{name: "missing key", err: validateAPIKey(), want: exitAuth},
+		{name: "unauthorized", err: apiErr(http.StatusUnauthorized), want: exitAuth},
+		{name: "not found", err: apiErr(http.StatusNotFound), want: exitNotFound},
+		{name: "timeout", err: fmt.Errorf("%w after 30s", api.ErrTimeout), want: exitNetwork},
+		{name: "network", err: fmt.Errorf("error making request: %w", &url.Error{Op: "Get", URL: "http://x", Err: errors.New("connection refused")}), want: exitNetwork},
+		{name: "cancelled", err: fmt.Errorf("error uploading file: %w", context.Canceled), want: exitCancelled},
+	}
+
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestArgErrorsAreUsageErrors(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "0x45"}
+	rootCmd.AddCommand(handlers.NewStatsCmd())
+	markArgErrors(rootCmd)
+	rootCmd.SetArgs([]string{"stats"})
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+
+	if code := exitCode(rootCmd.Execute()); code != exitUsage {
+		t.Errorf("Expected missing argument to exit with %d, got %d", exitUsage, code)
+	}
+}
+
+func TestConfigCommandsApplyGlobalFlags(t *testing.T) {
+	cleanup, tmpDir := setupTestEnv(t)
+	defer cleanup()
+
+	// Config commands run without an API key, and with a header that
+	// would stop any request from being made.
+	viper.Set("headers", []string{"not a header"})
+	out := filepath.Join(tmpDir, "out", "config.txt")
+	rootCmd, closeOutput := newRootCmd()
+	rootCmd.SetArgs([]string{"config", "list", "-o", out})
+	rootCmd.SetErr(&bytes.Buffer{})
+	err := rootCmd.Execute()
+	if closeErr := closeOutput(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected config list to write to --output, got %v", err)
+	}
+	if !strings.Contains(string(data), "api_url") {
+		t.Errorf("Expected the settings in the output file, got:\n%s", data)
+	}
+}
+
+func TestAnonymousUpload(t *testing.T) {
+	cleanup, _ := setupTestEnv(t)
+	defer cleanup()
+
+	var uploads int
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		auth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+	viper.Set("api_url", server.URL)
+
+	run := func(args ...string) (string, error) {
+		rootCmd, closeOutput := newRootCmd()
+		var out bytes.Buffer
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(&bytes.Buffer{})
+		err := rootCmd.Execute()
+		_ = closeOutput()
+		return out.String(), err
+	}
+
+	output, err := run("upload", "-C", "hello")
+	if err != nil || uploads != 1 || auth != "" {
+		t.Fatalf("Expected an anonymous upload, got %v after %d uploads with %q", err, uploads, auth)
+	}
+	if !strings.Contains(output, "https://0x45.st/abc123") {
+		t.Errorf("Expected the paste URL, got:\n%s", output)
+	}
+
+	_, err = run("upload", "-C", "hello", "--private")
+	if code := exitCode(err); code != exitUsage || !strings.Contains(err.Error(), "private uploads require an API key") {
+		t.Errorf("Expected a private anonymous upload to be a usage error, got %v (exit %d)", err, code)
+	}
+
+	_, err = run("list", "pastes")
+	if code := exitCode(err); code != exitAuth {
+		t.Errorf("Expected listing without a key to exit %d, got %v (exit %d)", exitAuth, err, code)
+	}
+	if uploads != 1 {
+		t.Errorf("Expected refused commands to send nothing, got %d requests", uploads)
+	}
+}
+
+func TestAnonymousUploadExpiry(t *testing.T) {
+	cleanup, _ := setupTestEnv(t)
+	defer cleanup()
+
+	var expires []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expires = append(expires, r.URL.Query().Get("expires"))
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+	viper.Set("api_url", server.URL)
+
+	run := func(args ...string) error {
+		rootCmd, closeOutput := newRootCmd()
+		rootCmd.SetArgs(args)
+		rootCmd.SetOut(&bytes.Buffer{})
+		rootCmd.SetErr(&bytes.Buffer{})
+		err := rootCmd.Execute()
+		_ = closeOutput()
+		return err
+	}
+
+	err := run("upload", "-C", "hello", "--expires", "none")
+	if code := exitCode(err); code != exitUsage || !strings.Contains(err.Error(), "requires an API key") {
+		t.Errorf("Expected --expires none without a key to be a usage error, got %v (exit %d)", err, code)
+	}
+	err = run("upload", "-C", "hello", "--expires", "200d")
+	if code := exitCode(err); code != exitUsage || !strings.Contains(err.Error(), "maximum of 128 days") {
+		t.Errorf("Expected the anonymous expiry limit, got %v (exit %d)", err, code)
+	}
+	if len(expires) != 0 {
+		t.Fatalf("Expected refused uploads to send nothing, got %v", expires)
+	}
+
+	if err := run("upload", "-C", "hello", "--expires", "100d"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("upload", "-C", "hello", "--expires", "200d", "--api-key", "test-key"); err != nil {
+		t.Errorf("Expected a key to allow the longer expiry, got %v", err)
+	}
+	if err := run("upload", "-C", "hello", "--expires", "none", "--api-key", "test-key"); err != nil {
+		t.Errorf("Expected a key to allow no expiry, got %v", err)
+	}
+	if len(expires) != 3 {
+		t.Errorf("Expected three uploads, got %v", expires)
+	}
+}
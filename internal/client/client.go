@@ -1,39 +1,283 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/spf13/viper"
-	"github.com/watzon/0x45-cli/pkg/api/paste69"
+	"github.com/watzon/0x45-cli/internal/logging"
+	api "github.com/watzon/0x45-cli/pkg/client"
 )
 
-var client *paste69.Client
+var client *api.Client
+
+// fetchClient downloads arbitrary URLs for Fetch.
+var fetchClient = http.DefaultClient
+
+// ctx is the context requests are made with. It is cancelled when the user
+// interrupts the CLI.
+var ctx = context.Background()
+
+// SetContext sets the context that subsequent requests are made with.
+func SetContext(c context.Context) {
+	ctx = c
+}
+
+// Initialize builds the shared API client from the current configuration.
+func Initialize() error {
+	apiKey, err := APIKey()
+	if err != nil {
+		return err
+	}
 
-func Initialize() {
-	client = paste69.NewClient(
+	client = api.NewClient(
 		viper.GetString("api_url"),
-		viper.GetString("api_key"),
+		apiKey,
+		viper.GetDuration("request_timeout"),
 	)
+
+	if header := viper.GetString("auth_header"); header != "" {
+		client.AuthHeader = header
+	}
+
+	switch via := viper.GetString("filename_via"); strings.ToLower(via) {
+	case "":
+	case api.FilenameBoth, api.FilenameHeader, api.FilenameQuery:
+		client.FilenameVia = strings.ToLower(via)
+	default:
+		return fmt.Errorf("invalid filename_via %q: must be header, query or both", via)
+	}
+
+	proxy, err := proxyFunc(viper.GetString("http_proxy"), viper.GetString("no_proxy"), viper.GetBool("disable_proxy"))
+	if err != nil {
+		return err
+	}
+	transport := api.NewTransport(client.Timeout)
+	transport.Proxy = proxy
+	client.HTTPClient.Transport = transport
+
+	// Redirects are followed unless follow_redirects is turned off, e.g.
+	// with --no-follow, in which case the redirect itself is the response
+	// and its Location can be inspected.
+	if viper.IsSet("follow_redirects") && !viper.GetBool("follow_redirects") {
+		client.HTTPClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	// Remote downloads go through the same proxy and redirect policy, but
+	// on a client of their own so nothing bounds how long the body takes.
+	fetchClient = &http.Client{Transport: transport, CheckRedirect: client.HTTPClient.CheckRedirect}
+
+	headers, err := ParseHeaders(ConfiguredHeaders())
+	if err != nil {
+		return err
+	}
+	client.Headers = headers
+
+	// The request trace is debug output; --verbose turns it on.
+	client.Logger = logging.Writer(logging.Debug)
+
+	return nil
 }
 
 func init() {
-	Initialize()
+	_ = Initialize()
+}
+
+// ConfiguredHeaders returns the headers setting: a list in the config file,
+// or one header per line when it comes from OX45_HEADERS, since header
+// values may contain the spaces and commas a list would be split on.
+func ConfiguredHeaders() []string {
+	value, ok := viper.Get("headers").(string)
+	if !ok {
+		return viper.GetStringSlice("headers")
+	}
+	var headers []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			headers = append(headers, line)
+		}
+	}
+	return headers
+}
+
+// headerName matches a valid HTTP header field name.
+var headerName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// ParseHeaders parses custom request headers given as "Key: Value".
+func ParseHeaders(values []string) (http.Header, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	headers := make(http.Header)
+	for _, value := range values {
+		name, val, ok := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		val = strings.TrimSpace(val)
+		if !ok || !headerName.MatchString(name) || strings.ContainsAny(val, "\r\n") {
+			return nil, fmt.Errorf("invalid header %q (expected \"Key: Value\")", value)
+		}
+		headers.Add(name, val)
+	}
+	return headers, nil
+}
+
+// APIKey returns the configured API key with any file: or env: reference
+// resolved.
+func APIKey() (string, error) {
+	return ResolveAPIKey(viper.GetString("api_key"))
+}
+
+// Longest expiry the server accepts by default, in days, depending on
+// whether the request is made with an API key.
+const (
+	DefaultMaxExpiryDays     = 730
+	DefaultMaxExpiryDaysAnon = 128
+)
+
+// MaxExpiryForKey returns the longest expiry the server accepts, with or
+// without an API key. Servers with other limits can be matched with the
+// max_expiry_days and max_expiry_days_anon settings; values that aren't a
+// positive number of days fall back to the defaults.
+func MaxExpiryForKey(hasKey bool) time.Duration {
+	key, days := "max_expiry_days_anon", DefaultMaxExpiryDaysAnon
+	if hasKey {
+		key, days = "max_expiry_days", DefaultMaxExpiryDays
+	}
+	if n := viper.GetInt(key); n > 0 {
+		days = n
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ResolveAPIKey resolves an API key setting. Values of the form
+// "file:/path/to/keyfile" are read from that file and "env:NAME" from the
+// named environment variable; anything else is returned as is.
+func ResolveAPIKey(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "file:"):
+		path := strings.TrimPrefix(value, "file:")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("could not read API key file: %w", err)
+		}
+		return strings.TrimRightFunc(string(data), unicode.IsSpace), nil
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		key, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("API key environment variable %s is not set", name)
+		}
+		return strings.TrimRightFunc(key, unicode.IsSpace), nil
+	default:
+		return value, nil
+	}
+}
+
+// BaseURL returns the API URL requests are sent to.
+func BaseURL() string {
+	return client.BaseURL
+}
+
+func UploadFile(filePath string, private bool, expires string) (*api.UploadResponse, error) {
+	return client.Upload(ctx, filePath, private, expires)
+}
+
+func UploadReader(body io.Reader, size int64, opts api.UploadOptions) (*api.UploadResponse, error) {
+	return client.UploadReader(ctx, body, size, opts)
+}
+
+func StartChunkedUpload(size int64, opts api.UploadOptions) (*api.ChunkedUploadResponse, error) {
+	return client.StartChunkedUpload(ctx, size, opts)
+}
+
+func ChunkedUploadStatus(uploadID string) (*api.ChunkedUploadResponse, error) {
+	return client.ChunkedUploadStatus(ctx, uploadID)
+}
+
+func UploadChunk(uploadID string, offset int64, chunk []byte) (*api.ChunkedUploadResponse, error) {
+	return client.UploadChunk(ctx, uploadID, offset, chunk)
+}
+
+func CompleteChunkedUpload(uploadID string) (*api.UploadResponse, error) {
+	return client.CompleteChunkedUpload(ctx, uploadID)
+}
+
+func ReplacePaste(id string, body io.Reader, size int64, opts api.UploadOptions) (*api.UploadResponse, error) {
+	return client.ReplacePaste(ctx, id, body, size, opts)
+}
+
+func ShortenURL(url string, private bool, expires string) (*api.ShortenResponse, error) {
+	return client.Shorten(ctx, url, private, expires)
+}
+
+func Delete(id string) (*api.GenericResponse, error) {
+	return client.Delete(ctx, id)
+}
+
+func ListPastes(opts api.ListOptions) (*api.ListResponse[api.PasteListItem], error) {
+	return client.ListPastes(ctx, opts)
+}
+
+func ListURLs(opts api.ListOptions) (*api.ListResponse[api.URLListItem], error) {
+	return client.ListURLs(ctx, opts)
+}
+
+func ListKeys() (*api.ListResponse[api.KeyListItem], error) {
+	return client.ListKeys(ctx)
+}
+
+func RevokeKey(id string) (*api.GenericResponse, error) {
+	return client.RevokeKey(ctx, id)
+}
+
+func GetURLStats(id string) (*api.URLStatsResponse, error) {
+	return client.GetURLStats(ctx, id)
 }
 
-func UploadFile(filePath string, private bool, expires string) (*paste69.UploadResponse, error) {
-	return client.Upload(filePath, private, expires)
+func UpdateURLExpiration(id string, expires string) (*api.UpdateExpirationResponse, error) {
+	return client.UpdateURLExpiration(ctx, id, expires)
 }
 
-func ShortenURL(url string, private bool, expires string) (*paste69.ShortenResponse, error) {
-	return client.Shorten(url, private, expires)
+func Download(id string) (*api.DownloadResponse, error) {
+	return client.Download(ctx, id)
 }
 
-func Delete(id string) (*paste69.GenericResponse, error) {
-	return client.Delete(id)
+func DownloadWithPassword(id, password string) (*api.DownloadResponse, error) {
+	return client.DownloadWithPassword(ctx, id, password)
 }
 
-func ListPastes(page, perPage int) (*paste69.ListResponse[paste69.PasteListItem], error) {
-	return client.ListPastes(page, perPage)
+// Fetch downloads rawURL through the configured proxy. Unlike API requests,
+// it sends neither the API key nor the custom headers, since the URL may
+// point anywhere. Callers must close the response body.
+func Fetch(rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching URL: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		if location := resp.Header.Get("Location"); location != "" {
+			return nil, fmt.Errorf("error fetching %s: %s, redirected to %s", rawURL, resp.Status, location)
+		}
+		return nil, fmt.Errorf("error fetching %s: %s", rawURL, resp.Status)
+	}
+	return resp, nil
 }
 
-func ListURLs(page, perPage int) (*paste69.ListResponse[paste69.URLListItem], error) {
-	return client.ListURLs(page, perPage)
+func Exists(id string, raw bool) (bool, error) {
+	return client.Exists(ctx, id, raw)
 }
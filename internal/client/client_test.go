@@ -1,13 +1,20 @@
 package client
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/watzon/0x45-cli/pkg/api/paste69"
+	"github.com/spf13/viper"
+	api "github.com/watzon/0x45-cli/pkg/client"
 )
 
 func setupTestServer() *httptest.Server {
@@ -18,7 +25,7 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.UploadResponse{
+			resp := api.UploadResponse{
 				Success:   true,
 				URL:       "https://0x45.st/abc123",
 				DeleteURL: "https://0x45.st/delete/abc123",
@@ -32,7 +39,7 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.ShortenResponse{
+			resp := api.ShortenResponse{
 				Success:   true,
 				URL:       "https://0x45.st/abc123",
 				DeleteURL: "https://0x45.st/delete/abc123",
@@ -46,10 +53,10 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.ListResponse[paste69.PasteListItem]{
+			resp := api.ListResponse[api.PasteListItem]{
 				Success: true,
 			}
-			resp.Data.Items = []paste69.PasteListItem{
+			resp.Data.Items = []api.PasteListItem{
 				{
 					Id:        "abc123",
 					Filename:  "test.txt",
@@ -67,7 +74,7 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.GenericResponse{
+			resp := api.GenericResponse{
 				Success: true,
 				Message: "Deleted successfully",
 			}
@@ -86,7 +93,7 @@ func TestUploadFile(t *testing.T) {
 	defer server.Close()
 
 	// Initialize a new client for each test
-	client = paste69.NewClient(server.URL, "test-key")
+	client = api.NewClient(server.URL, "test-key", 0)
 
 	// Create a temporary test file
 	tmpfile, err := os.CreateTemp("", "test")
@@ -120,7 +127,7 @@ func TestShortenURL(t *testing.T) {
 	defer server.Close()
 
 	// Initialize a new client for each test
-	client = paste69.NewClient(server.URL, "test-key")
+	client = api.NewClient(server.URL, "test-key", 0)
 
 	resp, err := ShortenURL("https://example.com", true, "24h")
 	if err != nil {
@@ -140,9 +147,9 @@ func TestListPastes(t *testing.T) {
 	defer server.Close()
 
 	// Initialize a new client for each test
-	client = paste69.NewClient(server.URL, "test-key")
+	client = api.NewClient(server.URL, "test-key", 0)
 
-	resp, err := ListPastes(1, 10)
+	resp, err := ListPastes(api.ListOptions{Page: 1, PerPage: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -160,7 +167,7 @@ func TestDelete(t *testing.T) {
 	defer server.Close()
 
 	// Initialize a new client for each test
-	client = paste69.NewClient(server.URL, "test-key")
+	client = api.NewClient(server.URL, "test-key", 0)
 
 	resp, err := Delete("abc123")
 	if err != nil {
@@ -174,3 +181,266 @@ func TestDelete(t *testing.T) {
 		t.Errorf("Expected message to be 'Deleted successfully', got %s", resp.Message)
 	}
 }
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client = api.NewClient(server.URL, "test-key", 10*time.Millisecond)
+
+	_, err := ShortenURL("https://example.com", false, "")
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if !strings.Contains(err.Error(), "request timed out after 10ms") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+}
+
+func TestFetchSlowBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for _, b := range []byte("slow") {
+			time.Sleep(30 * time.Millisecond)
+			_, _ = w.Write([]byte{b})
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("api_url", server.URL)
+	viper.Set("request_timeout", "50ms")
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := Fetch(server.URL + "/big.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "slow" {
+		t.Errorf("Expected a download outlasting request_timeout to finish, got %q, %v", body, err)
+	}
+}
+
+func TestVerboseLogging(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client = api.NewClient(server.URL, "secret-key-1234", 0)
+	client.Logger = &buf
+
+	if _, err := ShortenURL("https://example.com", false, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "> POST "+server.URL+"/shorten") {
+		t.Errorf("Expected request line in log, got %s", output)
+	}
+	if !strings.Contains(output, "< 200 OK") {
+		t.Errorf("Expected status line in log, got %s", output)
+	}
+	if strings.Contains(output, "secret-key-1234") {
+		t.Error("Expected API key to be redacted")
+	}
+	if !strings.Contains(output, "***********1234") {
+		t.Errorf("Expected last 4 characters of API key, got %s", output)
+	}
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("file-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OX45_TEST_KEY", "env-key")
+
+	tests := map[string]string{
+		"plain-key":         "plain-key",
+		"file:" + keyFile:   "file-key",
+		"env:OX45_TEST_KEY": "env-key",
+		"":                  "",
+	}
+	for input, want := range tests {
+		got, err := ResolveAPIKey(input)
+		if err != nil {
+			t.Errorf("ResolveAPIKey(%q): unexpected error: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ResolveAPIKey(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if _, err := ResolveAPIKey("file:" + filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing key file")
+	}
+	if _, err := ResolveAPIKey("env:OX45_TEST_MISSING"); err == nil {
+		t.Error("Expected error for unset environment variable")
+	}
+}
+
+func TestMaxExpiryForKey(t *testing.T) {
+	defer viper.Set("max_expiry_days", nil)
+	defer viper.Set("max_expiry_days_anon", nil)
+	const day = 24 * time.Hour
+
+	if got := MaxExpiryForKey(false); got != 128*day {
+		t.Errorf("Expected 128 days without a key by default, got %s", got)
+	}
+	if got := MaxExpiryForKey(true); got != 730*day {
+		t.Errorf("Expected 730 days with a key by default, got %s", got)
+	}
+
+	viper.Set("max_expiry_days_anon", 30)
+	viper.Set("max_expiry_days", "365")
+	if got := MaxExpiryForKey(false); got != 30*day {
+		t.Errorf("Expected the configured 30 days without a key, got %s", got)
+	}
+	if got := MaxExpiryForKey(true); got != 365*day {
+		t.Errorf("Expected the configured 365 days with a key, got %s", got)
+	}
+
+	for _, value := range []any{0, -5, "soon"} {
+		viper.Set("max_expiry_days", value)
+		if got := MaxExpiryForKey(true); got != 730*day {
+			t.Errorf("Expected max_expiry_days %v to fall back to 730 days, got %s", value, got)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders([]string{"X-Trace-Id: abc", "accept:  text/plain, */*", "X-Trace-Id: def"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Values("X-Trace-Id"); len(got) != 2 || got[0] != "abc" || got[1] != "def" {
+		t.Errorf("Expected repeated header values, got %v", got)
+	}
+	if got := headers.Get("Accept"); got != "text/plain, */*" {
+		t.Errorf("Expected values with commas to be kept, got %q", got)
+	}
+
+	for _, bad := range []string{"NoColon", ": value", "Bad Name: value", "X-Foo: a\r\nX-Bar: b"} {
+		if _, err := ParseHeaders([]string{bad}); err == nil {
+			t.Errorf("ParseHeaders(%q): expected error", bad)
+		}
+	}
+}
+
+func TestCustomHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_ = json.NewEncoder(w).Encode(api.ShortenResponse{Success: true})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client = api.NewClient(server.URL, "secret-key-1234", 0)
+	client.Headers = http.Header{"X-Cdn-Token": {"cdn"}, "Authorization": {"Basic dXNlcjpwYXNz"}}
+	client.Logger = &buf
+
+	if _, err := ShortenURL("https://example.com", false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Cdn-Token") != "cdn" || got.Get("Authorization") != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Expected custom headers to be sent and take precedence, got %v", got)
+	}
+	if strings.Contains(buf.String(), "dXNlcjpwYXNz") || !strings.Contains(buf.String(), "Authorization: Basic ") {
+		t.Errorf("Expected custom Authorization to be redacted in the log, got %s", buf.String())
+	}
+}
+
+func TestFilenameVia(t *testing.T) {
+	var header, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Filename")
+		query = r.URL.Query().Get("filename")
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true})
+	}))
+	defer server.Close()
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("api_url", server.URL)
+
+	tests := []struct {
+		via        string
+		wantHeader string
+		wantQuery  string
+	}{
+		{"", "notes.txt", "notes.txt"},
+		{"both", "notes.txt", "notes.txt"},
+		{"header", "notes.txt", ""},
+		{"Query", "", "notes.txt"},
+	}
+	for _, tt := range tests {
+		viper.Set("filename_via", tt.via)
+		if err := Initialize(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := UploadReader(strings.NewReader("hi"), 2, api.UploadOptions{Filename: "notes.txt"}); err != nil {
+			t.Fatal(err)
+		}
+		if header != tt.wantHeader || query != tt.wantQuery {
+			t.Errorf("filename_via %q: got header %q and query %q, want %q and %q", tt.via, header, query, tt.wantHeader, tt.wantQuery)
+		}
+	}
+
+	viper.Set("filename_via", "body")
+	if err := Initialize(); err == nil || !strings.Contains(err.Error(), "invalid filename_via") {
+		t.Errorf("Expected an invalid filename_via error, got %v", err)
+	}
+}
+
+func TestFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abc123", "/abc123/raw":
+			http.Redirect(w, r, "/target", http.StatusFound)
+		case "/target":
+			_, _ = w.Write([]byte("landed"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("api_url", server.URL)
+
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Fetch(server.URL + "/abc123")
+	if err != nil {
+		t.Fatalf("Expected redirects to be followed by default, got %v", err)
+	}
+	resp.Body.Close()
+
+	viper.Set("follow_redirects", false)
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Fetch(server.URL + "/abc123"); err == nil || !strings.Contains(err.Error(), "302 Found, redirected to /target") {
+		t.Errorf("Expected the redirect to be returned, got %v", err)
+	}
+	_, err = Download("abc123")
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusFound {
+		t.Fatalf("Expected a 302 APIError, got %v", err)
+	}
+	if err.Error() != "redirected to /target (status code 302)" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+}
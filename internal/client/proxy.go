@@ -0,0 +1,63 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyFunc returns the Transport.Proxy for the client. With disabled set,
+// requests always go direct. A configured proxyURL overrides the
+// HTTP_PROXY/HTTPS_PROXY environment, except for hosts matched by noProxy;
+// without one the environment is used as usual.
+func proxyFunc(proxyURL, noProxy string, disabled bool) (func(*http.Request) (*url.URL, error), error) {
+	if disabled {
+		return nil, nil
+	}
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxy, err := url.Parse(proxyURL)
+	if err != nil || proxy.Host == "" {
+		return nil, fmt.Errorf("invalid http_proxy: %s", proxyURL)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid http_proxy: %s (scheme must be http, https or socks5)", proxyURL)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}, nil
+}
+
+// bypassProxy reports whether host is matched by a comma-separated no_proxy
+// list. As with NO_PROXY, "*" matches every host and a domain entry matches
+// the domain and its subdomains.
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestProxyConfig(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.Host + r.URL.Path
+		_ = json.NewEncoder(w).Encode(api.ShortenResponse{Success: true})
+	}))
+	defer proxy.Close()
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("api_url", "http://paste.example.invalid")
+	viper.Set("http_proxy", proxy.URL)
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ShortenURL("https://example.com", false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if proxied != "paste.example.invalid/shorten" {
+		t.Errorf("Expected the request to go through the proxy, got %q", proxied)
+	}
+
+	viper.Set("http_proxy", "ftp://proxy.example.com")
+	if err := Initialize(); err == nil {
+		t.Error("Expected an unsupported proxy scheme to be rejected")
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	req := func(rawURL string) *http.Request {
+		r, _ := http.NewRequest("GET", rawURL, nil)
+		return r
+	}
+
+	proxy, err := proxyFunc("http://proxy.local:3128", "internal.example.com, .corp", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"https://0x45.st/upload":                 true,
+		"https://internal.example.com/upload":    false,
+		"https://paste.internal.example.com/x":   false,
+		"https://notinternal.example.com/upload": true,
+		"https://paste.corp/upload":              false,
+	}
+	for rawURL, wantProxy := range tests {
+		u, err := proxy(req(rawURL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (u != nil) != wantProxy {
+			t.Errorf("%s: proxied = %v, want %v", rawURL, u != nil, wantProxy)
+		}
+	}
+
+	if proxy, _ := proxyFunc("http://proxy.local:3128", "", true); proxy != nil {
+		t.Error("Expected --no-proxy to disable the proxy")
+	}
+	if !bypassProxy("anything.example.com", "*") {
+		t.Error("Expected * to match every host")
+	}
+}
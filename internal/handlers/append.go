@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// appendSize checks that every --append path is a regular file and returns
+// their combined size, so nothing is uploaded when one of them is missing.
+func appendSize(paths []string) (int64, error) {
+	var total int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			return 0, usageErrorf("file does not exist: %s", path)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error getting file info: %w", err)
+		}
+		if info.IsDir() {
+			return 0, usageErrorf("%s is a directory: --append only takes files", path)
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+// openAppend opens the --append files as one stream, read in the order
+// given. The returned function closes all of them.
+func openAppend(paths []string) (io.Reader, func(), error) {
+	files := make([]*os.File, 0, len(paths))
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("error opening file: %w", err)
+		}
+		files = append(files, f)
+		readers = append(readers, f)
+	}
+	return io.MultiReader(readers...), closeAll, nil
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerAppend(t *testing.T) {
+	dir := t.TempDir()
+	header := filepath.Join(dir, "header.log")
+	body := filepath.Join(dir, "body.log")
+	if err := os.WriteFile(header, []byte("== header ==\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(body, []byte("line 1\nline 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var filename, uploaded string
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		data, _ := io.ReadAll(r.Body)
+		filename, uploaded = r.Header.Get("X-Filename"), string(data)
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	_ = cmd.Flags().Set("append", header)
+	_ = cmd.Flags().Set("append", body)
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != "== header ==\nline 1\nline 2\n" || filename != "header.log" {
+		t.Errorf("Unexpected upload: filename %q, body %q", filename, uploaded)
+	}
+	if !strings.Contains(stderr.String(), "Combining 2 files (27 B)") {
+		t.Errorf("Expected the combined size to be reported, got %q", stderr.String())
+	}
+
+	cmd = NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	_ = cmd.Flags().Set("append", header)
+	_ = cmd.Flags().Set("append", body)
+	_ = cmd.Flags().Set("filename", "combined.log")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if filename != "combined.log" {
+		t.Errorf("Expected --filename to override the name, got %q", filename)
+	}
+
+	// A missing part fails before anything is uploaded.
+	uploads = 0
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("append", header)
+	_ = cmd.Flags().Set("append", filepath.Join(dir, "missing.log"))
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) || uploads != 0 {
+		t.Errorf("Expected a usage error and no upload, got %v and %d uploads", err, uploads)
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("append", header)
+	if err := Upload(cmd, []string{body}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error with a file argument, got %v", err)
+	}
+}
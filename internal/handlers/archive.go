@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFile lists glob patterns to leave out of directory archives, one per
+// line, in the spirit of .gitignore.
+const ignoreFile = ".0x45ignore"
+
+// Archive formats accepted by upload --archive.
+const (
+	archiveTarGz = "tar.gz"
+	archiveZip   = "zip"
+)
+
+// archiveMimeTypes is the content type sent for each archive format.
+var archiveMimeTypes = map[string]string{
+	archiveTarGz: "application/gzip",
+	archiveZip:   "application/zip",
+}
+
+func validateArchiveFormat(format string) error {
+	if _, ok := archiveMimeTypes[format]; !ok {
+		return usageErrorf("invalid archive format: %s (must be tar.gz or zip)", format)
+	}
+	return nil
+}
+
+// countingReader tracks how many bytes have been read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// streamArchive returns a reader producing an archive of dir as it is read.
+// Closing the reader stops the archiver if the upload is abandoned early.
+func streamArchive(dir, format string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeArchive(pw, dir, format))
+	}()
+	return pr
+}
+
+// writeArchive writes dir to w as a tar.gz or zip archive rooted at the
+// directory's own name. Paths matched by the directory's .0x45ignore are
+// left out, as are symlinks that resolve outside the directory.
+func writeArchive(w io.Writer, dir, format string) error {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+
+	patterns, err := loadIgnorePatterns(root)
+	if err != nil {
+		return err
+	}
+
+	var add func(name string, info fs.FileInfo, file string, link string) error
+	var finish func() error
+
+	switch format {
+	case archiveTarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		add = func(name string, info fs.FileInfo, file string, link string) error {
+			hdr, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if info.IsDir() {
+				hdr.Name += "/"
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			return copyFile(tw, file)
+		}
+		finish = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			return gz.Close()
+		}
+	case archiveZip:
+		zw := zip.NewWriter(w)
+		add = func(name string, info fs.FileInfo, file string, link string) error {
+			hdr, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if info.IsDir() {
+				hdr.Name += "/"
+			} else {
+				hdr.Method = zip.Deflate
+			}
+			fw, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+			switch {
+			case link != "":
+				_, err = io.WriteString(fw, link)
+				return err
+			case info.Mode().IsRegular():
+				return copyFile(fw, file)
+			}
+			return nil
+		}
+		finish = zw.Close
+	default:
+		return validateArchiveFormat(format)
+	}
+
+	base := filepath.Base(root)
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && isIgnored(patterns, rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if !symlinkWithin(root, p) {
+				return nil
+			}
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if !info.IsDir() && !info.Mode().IsRegular() {
+			// Sockets, devices and the like can't be archived meaningfully.
+			return nil
+		}
+
+		name := base
+		if rel != "." {
+			name = path.Join(base, rel)
+		}
+		return add(name, info, p, link)
+	})
+	if err != nil {
+		return fmt.Errorf("error archiving directory: %w", err)
+	}
+
+	if err := finish(); err != nil {
+		return fmt.Errorf("error archiving directory: %w", err)
+	}
+	return nil
+}
+
+func copyFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// symlinkWithin reports whether the symlink at p resolves to a path inside
+// root. Dangling links are treated as escaping.
+func symlinkWithin(root, p string) bool {
+	target, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// loadIgnorePatterns reads the .0x45ignore file in root, skipping blank lines
+// and # comments. A missing file means nothing is ignored.
+func loadIgnorePatterns(root string) ([]string, error) {
+	f, err := os.Open(filepath.Join(root, ignoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", ignoreFile, err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := path.Match(strings.Trim(line, "/"), ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern in %s: %s", ignoreFile, line)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", ignoreFile, err)
+	}
+	return patterns, nil
+}
+
+// isIgnored matches rel, a slash-separated path relative to the archive
+// root, against the ignore patterns. Patterns without a slash match any path
+// component's base name; patterns with a leading or inner slash match the
+// whole relative path. A trailing slash restricts a pattern to directories.
+func isIgnored(patterns []string, rel string, isDir bool) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), rel); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func setupArchiveDir(t *testing.T) string {
+	t.Helper()
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "project")
+	files := map[string]string{
+		"main.go":           "package main",
+		"docs/readme.md":    "# readme",
+		"debug.log":         "noise",
+		"build/output.bin":  "binary",
+		"docs/notes.log":    "more noise",
+		".0x45ignore":       "# build artifacts\n*.log\nbuild/\n",
+		"../outside/secret": "secret",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Symlink(filepath.Join(parent, "outside", "secret"), filepath.Join(dir, "escape")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(dir, "inside")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+var wantArchiveEntries = []string{
+	"project/",
+	"project/.0x45ignore",
+	"project/docs/",
+	"project/docs/readme.md",
+	"project/inside",
+	"project/main.go",
+}
+
+func TestWriteArchiveTarGz(t *testing.T) {
+	dir := setupArchiveDir(t)
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, dir, archiveTarGz); err != nil {
+		t.Fatal(err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "project/inside" && (hdr.Typeflag != tar.TypeSymlink || hdr.Linkname != "main.go") {
+			t.Errorf("Expected inside to be a symlink to main.go, got %+v", hdr)
+		}
+	}
+
+	assertEntries(t, names)
+}
+
+func TestWriteArchiveZip(t *testing.T) {
+	dir := setupArchiveDir(t)
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, dir, archiveZip); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	assertEntries(t, names)
+}
+
+func assertEntries(t *testing.T, names []string) {
+	t.Helper()
+
+	sort.Strings(names)
+	if len(names) != len(wantArchiveEntries) {
+		t.Fatalf("Expected entries %v, got %v", wantArchiveEntries, names)
+	}
+	for i := range names {
+		if names[i] != wantArchiveEntries[i] {
+			t.Fatalf("Expected entries %v, got %v", wantArchiveEntries, names)
+		}
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	patterns := []string{"*.log", "build/", "/docs/private.md", "vendor"}
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{rel: "app.log", want: true},
+		{rel: "nested/app.log", want: true},
+		{rel: "build", isDir: true, want: true},
+		{rel: "build", want: false},
+		{rel: "docs/private.md", want: true},
+		{rel: "other/docs/private.md", want: false},
+		{rel: "src/vendor", isDir: true, want: true},
+		{rel: "main.go", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isIgnored(patterns, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("isIgnored(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
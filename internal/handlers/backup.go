@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// manifestFile records what a backup directory contains, keyed by paste ID.
+const manifestFile = "manifest.json"
+
+// backupEntry is the manifest record for one paste.
+type backupEntry struct {
+	File      string  `json:"file"`
+	Filename  string  `json:"filename"`
+	Size      int64   `json:"size"`
+	CreatedAt string  `json:"created_at"`
+	URL       string  `json:"url"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+}
+
+// backupResult reports the outcome of backing up one paste.
+type backupResult struct {
+	Id     string `json:"id"`
+	File   string `json:"file"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [dir]",
+		Short: "Download all your pastes into a directory",
+		Long: `Download the raw content of every paste into a directory, along with a
+manifest.json describing them. Files that already exist are skipped, so
+running it again only fetches new pastes.`,
+		Args: cobra.ExactArgs(1),
+		RunE: Backup,
+	}
+
+	cmd.Flags().String("since", "", "Only back up pastes created on or after this date (YYYY-MM-DD or RFC3339)")
+
+	return cmd
+}
+
+func Backup(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+	dir := args[0]
+
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return err
+	}
+	dates, err := parseDateRange(since, "")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	resp, err := fetchAll(client.ListPastes, api.ListOptions{})
+	if err != nil {
+		return wrapAPIError("error listing pastes", err)
+	}
+
+	// Names are assigned from the full list so they stay the same between
+	// runs, whatever --since selects.
+	names := backupNames(resp.Data.Items)
+
+	manifest, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	var results []backupResult
+	failed := 0
+	for _, item := range resp.Data.Items {
+		if !dates.contains(item.CreatedAt) {
+			continue
+		}
+
+		result := backupResult{Id: item.Id, File: names[item.Id], Status: "saved"}
+		path := filepath.Join(dir, result.File)
+		if _, err := os.Stat(path); err == nil {
+			result.Status = "skipped"
+		} else if err := downloadTo(item.Id, path); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			failed++
+		}
+		results = append(results, result)
+
+		if result.Status != "failed" {
+			manifest[item.Id] = backupEntry{
+				File:      result.File,
+				Filename:  item.Filename,
+				Size:      item.Size,
+				CreatedAt: item.CreatedAt,
+				URL:       item.URL,
+				ExpiresAt: item.ExpiresAt,
+			}
+		}
+	}
+
+	if err := writeManifest(dir, manifest); err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if err := printJSON(cmd, results); err != nil {
+			return err
+		}
+	} else {
+		saved, skipped := 0, 0
+		for _, result := range results {
+			switch result.Status {
+			case "saved":
+				saved++
+			case "skipped":
+				skipped++
+			case "failed":
+				fmt.Fprintln(cmd.OutOrStdout(), theme.FormatError(fmt.Sprintf("%s: %s", result.Id, result.Error)))
+			}
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("Backed up %d pastes to %s (%d already present)", saved, dir, skipped)))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", failed, len(results))
+	}
+	return nil
+}
+
+// backupNames picks a local file name for each paste. Pastes sharing a
+// filename, or without one, get their ID added to keep the names distinct.
+func backupNames(items []api.PasteListItem) map[string]string {
+	base := func(item api.PasteListItem) string {
+		name := filepath.Base(filepath.FromSlash(item.Filename))
+		if name == "." || name == string(filepath.Separator) || name == manifestFile {
+			return ""
+		}
+		return name
+	}
+
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[strings.ToLower(base(item))]++
+	}
+
+	names := make(map[string]string, len(items))
+	for _, item := range items {
+		name := base(item)
+		switch {
+		case name == "":
+			name = item.Id
+		case counts[strings.ToLower(name)] > 1:
+			ext := fileExt(name)
+			name = strings.TrimSuffix(name, ext) + "-" + item.Id + ext
+		}
+		names[item.Id] = name
+	}
+	return names
+}
+
+// downloadTo saves the raw content of paste id to path. The content is
+// written to a temporary file first so an interrupted download isn't
+// mistaken for a finished one on the next run.
+func downloadTo(id, path string) error {
+	resp, err := client.Download(id)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			return notFoundErrorf("paste not found: %s", id)
+		}
+		return wrapAPIError("error downloading paste", err)
+	}
+	defer resp.Body.Close()
+
+	tmp := path + ".part"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// readManifest loads the manifest of an earlier backup in dir, if any.
+func readManifest(dir string) (map[string]backupEntry, error) {
+	manifest := make(map[string]backupEntry)
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", manifestFile, err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", manifestFile, err)
+	}
+	return manifest, nil
+}
+
+func writeManifest(dir string, manifest map[string]backupEntry) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", manifestFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", manifestFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestBackupNames(t *testing.T) {
+	names := backupNames([]api.PasteListItem{
+		{Id: "a1", Filename: "notes.txt"},
+		{Id: "b2", Filename: "Notes.txt"},
+		{Id: "c3", Filename: "main.go"},
+		{Id: "d4", Filename: ""},
+		{Id: "e5", Filename: "../../etc/passwd"},
+		{Id: "f6", Filename: ".bashrc"},
+		{Id: "g7", Filename: ".bashrc"},
+		{Id: "h8", Filename: "site.tar.gz"},
+		{Id: "i9", Filename: "site.tar.gz"},
+	})
+
+	want := map[string]string{
+		"a1": "notes-a1.txt",
+		"b2": "Notes-b2.txt",
+		"c3": "main.go",
+		"d4": "d4",
+		"e5": "passwd",
+		"f6": ".bashrc-f6",
+		"g7": ".bashrc-g7",
+		"h8": "site-h8.tar.gz",
+		"i9": "site-i9.tar.gz",
+	}
+	for id, name := range want {
+		if names[id] != name {
+			t.Errorf("backupNames()[%s] = %q, want %q", id, names[id], name)
+		}
+	}
+}
+
+func TestBackupHandler(t *testing.T) {
+	var downloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pastes" {
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{
+				{Id: "new1", Filename: "new.txt", CreatedAt: "2024-06-01T00:00:00Z"},
+				{Id: "old1", Filename: "old.txt", CreatedAt: "2023-01-01T00:00:00Z"},
+				{Id: "have1", Filename: "have.txt", CreatedAt: "2024-06-02T00:00:00Z"},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/raw")
+		downloads = append(downloads, id)
+		w.Write([]byte("content of " + id))
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "have.txt"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBackupCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("since", "2024-01-01")
+
+	if err := Backup(cmd, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(downloads) != 1 || downloads[0] != "new1" {
+		t.Errorf("Expected only new1 to be downloaded, got %v", downloads)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "new.txt"))
+	if string(data) != "content of new1" {
+		t.Errorf("Unexpected backup content: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "have.txt")); string(data) != "local" {
+		t.Errorf("Expected existing file to be left alone, got %q", data)
+	}
+
+	manifest, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) != 2 || manifest["new1"].File != "new.txt" || manifest["have1"].Filename != "have.txt" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	if !strings.Contains(buf.String(), "Backed up 1 pastes") {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+
+	_ = cmd.Flags().Set("since", "yesterday")
+	if err := Backup(cmd, []string{dir}); err == nil {
+		t.Error("Expected invalid --since to be rejected")
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// base64Reader decodes standard base64 as it is read, so large input is
+// never held in memory. Whitespace is ignored, so wrapped output such as
+// that of base64(1) can be passed as is, and padding is optional. Malformed
+// input fails the read with a usage error naming source, which is also kept
+// in err so it can be reported over whatever error the failed read caused.
+type base64Reader struct {
+	dec    io.Reader
+	source string
+	err    error
+}
+
+func newBase64Reader(r io.Reader, source string) *base64Reader {
+	return &base64Reader{
+		dec:    base64.NewDecoder(base64.StdEncoding, &base64Input{r: r, source: source}),
+		source: source,
+	}
+}
+
+func (b *base64Reader) Read(p []byte) (int, error) {
+	n, err := b.dec.Read(p)
+	var usageErr *UsageError
+	var corrupt base64.CorruptInputError
+	switch {
+	case errors.As(err, &usageErr):
+		b.err = err
+	case errors.As(err, &corrupt):
+		// Every other character is checked by base64Input.
+		b.err = usageErrorf("invalid base64 in %s: padding before the end", b.source)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		b.err = usageErrorf("invalid base64 in %s: it ends partway through a character", b.source)
+	default:
+		return n, err
+	}
+	return n, b.err
+}
+
+// decodeBase64 decodes all of s, for input that is already in memory.
+func decodeBase64(s, source string) ([]byte, error) {
+	return io.ReadAll(newBase64Reader(bytes.NewReader([]byte(s)), source))
+}
+
+// base64Input strips whitespace from base64 and pads its end, neither of
+// which the decoder accepts. It also reports characters outside the
+// alphabet, since only it knows where they are in the whole input.
+type base64Input struct {
+	r      io.Reader
+	source string
+	// n counts the characters passed on, for errors and the padding.
+	n   int64
+	eof bool
+	pad []byte
+}
+
+func (in *base64Input) Read(p []byte) (int, error) {
+	for !in.eof {
+		n, err := in.r.Read(p)
+		kept := p[:0]
+		for _, c := range p[:n] {
+			switch {
+			case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\v' || c == '\f':
+			case c == '=' || c == '+' || c == '/' || '0' <= c && c <= '9' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z':
+				kept = append(kept, c)
+			default:
+				return 0, usageErrorf("invalid base64 in %s at character %d (after removing whitespace)", in.source, in.n+int64(len(kept))+1)
+			}
+		}
+		in.n += int64(len(kept))
+
+		if errors.Is(err, io.EOF) {
+			in.eof = true
+			if rest := in.n % 4; rest >= 2 {
+				in.pad = bytes.Repeat([]byte("="), int(4-rest))
+			}
+			err = nil
+		}
+		if len(kept) > 0 || err != nil {
+			return len(kept), err
+		}
+	}
+
+	n := copy(p, in.pad)
+	in.pad = in.pad[n:]
+	if len(in.pad) == 0 {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// copyBase64 writes the content of r to w as a single line of base64,
+// returning the number of bytes written.
+func copyBase64(w io.Writer, r io.Reader) (int64, error) {
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	n, err := io.Copy(enc, r)
+	if err != nil {
+		return 0, err
+	}
+	if err := enc.Close(); err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return 0, err
+	}
+	return int64(base64.StdEncoding.EncodedLen(int(n))) + 1, nil
+}
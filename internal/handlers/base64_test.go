@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"aGVsbG8=", "hello", true},
+		{"aGVsbG8", "hello", true},
+		{"aGVs\nbG8=\n", "hello", true},
+		{"", "", true},
+		{"aGVsbG8*", "", false},
+		{"aGVsbG8==", "", false},
+		{"a", "", false},
+		{"aGk=\n", "hi", true},
+		{"aG k", "hi", true},
+	}
+	for _, tt := range tests {
+		got, err := decodeBase64(tt.in, "stdin")
+		if tt.ok && (err != nil || string(got) != tt.want) {
+			t.Errorf("decodeBase64(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+		var usageErr *UsageError
+		if !tt.ok && !errors.As(err, &usageErr) {
+			t.Errorf("decodeBase64(%q) = %v; want a usage error", tt.in, err)
+		}
+	}
+}
+
+// repeatReader yields b forever.
+type repeatReader struct {
+	b byte
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.b
+	}
+	return len(p), nil
+}
+
+func TestUploadAndGetBase64(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	var stored []byte
+	var filename string
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload":
+			uploads++
+			stored, _ = io.ReadAll(r.Body)
+			filename = r.Header.Get("X-Filename")
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+		case "/abc123/raw":
+			_, _ = w.Write(stored)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	upload := func(stdin string, args []string, flags ...string) error {
+		cmd := NewUploadCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader(stdin))
+		for i := 0; i < len(flags); i += 2 {
+			_ = cmd.Flags().Set(flags[i], flags[i+1])
+		}
+		return Upload(cmd, args)
+	}
+
+	if err := upload(encoded+"\n", nil, "base64", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, png) || filename != "paste.png" {
+		t.Errorf("Expected the decoded stdin to be uploaded as paste.png, got %q as %q", stored, filename)
+	}
+
+	stored = nil
+	if err := upload("", nil, "base64", "true", "content", encoded); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, png) || filename != "paste.png" {
+		t.Errorf("Expected the decoded --content to be uploaded as paste.png, got %q as %q", stored, filename)
+	}
+
+	var usageErr *UsageError
+	uploads = 0
+	err := upload("not base64!", nil, "base64", "true")
+	if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "invalid base64 in stdin") {
+		t.Errorf("Expected malformed stdin to be a usage error, got %v", err)
+	}
+	if err := upload("", nil, "base64", "true", "content", "%%%"); !errors.As(err, &usageErr) {
+		t.Errorf("Expected malformed --content to be a usage error, got %v", err)
+	}
+	if uploads != 0 {
+		t.Errorf("Expected nothing to be uploaded from malformed input, got %d uploads", uploads)
+	}
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := upload("", []string{file}, "base64", "true"); !errors.As(err, &usageErr) {
+		t.Errorf("Expected --base64 with a file to be a usage error, got %v", err)
+	}
+
+	stored = png
+	getCmd := NewGetCmd()
+	var buf bytes.Buffer
+	getCmd.SetOut(&buf)
+	getCmd.SetErr(&bytes.Buffer{})
+	_ = getCmd.Flags().Set("base64", "true")
+	if err := Get(getCmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != encoded+"\n" {
+		t.Errorf("Expected the content as base64, got %q", buf.String())
+	}
+}
+
+func TestUploadBase64Streams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	upload := func(stdin io.Reader, flags ...string) error {
+		cmd := NewUploadCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(stdin)
+		_ = cmd.Flags().Set("base64", "true")
+		for i := 0; i < len(flags); i += 2 {
+			_ = cmd.Flags().Set(flags[i], flags[i+1])
+		}
+		return Upload(cmd, nil)
+	}
+
+	// Input is decoded as it is uploaded, so endless input is stopped by
+	// --max-size rather than read into memory.
+	var usageErr *UsageError
+	err := upload(&repeatReader{b: 'A'}, "max-size", "1KB")
+	if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "larger than the maximum") {
+		t.Errorf("Expected endless input to hit --max-size, got %v", err)
+	}
+
+	// A mistake past the first bytes still fails the upload.
+	late := strings.Repeat("QUFB", 1000) + "!AAA"
+	err = upload(strings.NewReader(late))
+	if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "character 4001") {
+		t.Errorf("Expected a late mistake to fail the upload, got %v", err)
+	}
+}
@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+const (
+	// browsePageSize is how many items each request fetches while browsing.
+	browsePageSize = 25
+	// browseLookahead is how close the cursor gets to the last loaded item
+	// before the next page is fetched.
+	browseLookahead = 5
+	// browseChrome is the number of lines around the list: the title, its
+	// padding, the status line and the key help.
+	browseChrome = 4
+)
+
+func NewBrowseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "browse [pastes|urls]",
+		Short: "Browse your pastes and URLs interactively",
+		Long: `Browse your pastes or shortened URLs in a full-screen list. Pages are
+fetched as you scroll.
+
+Keys:
+  up/down, k/j  move
+  o             open in the browser
+  c             copy the URL
+  d             delete (asks for confirmation)
+  s             show click statistics for a URL
+  tab           switch between pastes and URLs
+  q, esc        quit`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: Browse,
+	}
+
+	return cmd
+}
+
+func Browse(cmd *cobra.Command, args []string) error {
+	kind := entryPaste
+	if len(args) > 0 {
+		switch args[0] {
+		case "pastes":
+		case "urls":
+			kind = entryURL
+		default:
+			return usageErrorf("invalid browse type %q: must be 'pastes' or 'urls'", args[0])
+		}
+	}
+
+	if jsonOutput() {
+		return usageErrorf("browse is interactive and doesn't support --json; use list instead")
+	}
+	if !canPrompt(cmd) {
+		return usageErrorf("browse needs an interactive terminal; use list instead")
+	}
+
+	opts := []tea.ProgramOption{
+		tea.WithAltScreen(),
+		tea.WithInput(cmd.InOrStdin()),
+		tea.WithOutput(cmd.OutOrStdout()),
+	}
+	if ctx := cmd.Context(); ctx != nil {
+		opts = append(opts, tea.WithContext(ctx))
+	}
+
+	final, err := tea.NewProgram(newBrowseModel(kind), opts...).Run()
+	if err != nil {
+		return fmt.Errorf("error running browser: %w", err)
+	}
+	// A failure to load the list is the one error worth reporting after
+	// the screen is gone; the rest were shown in the status line.
+	if m := final.(browseModel); m.err != nil && len(m.items) == 0 {
+		return m.err
+	}
+	return nil
+}
+
+// browsePageMsg delivers a page of items, or the error fetching it.
+type browsePageMsg struct {
+	kind    string
+	page    int
+	entries []listEntry
+	more    bool
+	err     error
+}
+
+// browseDeletedMsg reports the outcome of deleting an item.
+type browseDeletedMsg struct {
+	id  string
+	err error
+}
+
+// browseStatsMsg delivers the statistics for a shortened URL.
+type browseStatsMsg struct {
+	stats *api.URLStatsResponse
+	err   error
+}
+
+// browseStatusMsg reports the outcome of opening or copying an item.
+type browseStatusMsg struct {
+	status string
+	err    error
+}
+
+// browseModel is the state of the browse TUI.
+type browseModel struct {
+	kind   string
+	items  []listEntry
+	cursor int
+	// offset is the index of the first visible item.
+	offset int
+
+	page    int
+	more    bool
+	loading bool
+
+	confirming bool
+	stats      *api.URLStatsResponse
+	status     string
+	err        error
+
+	width, height int
+}
+
+func newBrowseModel(kind string) browseModel {
+	return browseModel{kind: kind, more: true, loading: true}
+}
+
+func (m browseModel) Init() tea.Cmd {
+	return fetchBrowsePage(m.kind, 1)
+}
+
+func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+
+	case browsePageMsg:
+		// A page requested before switching lists is dropped.
+		if msg.kind != m.kind {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.more = false
+			return m, nil
+		}
+		m.items = append(m.items, msg.entries...)
+		m.page = msg.page
+		m.more = msg.more
+		return m, m.loadMore()
+
+	case browseDeletedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		for i, entry := range m.items {
+			if entryID(entry) == msg.id {
+				m.items = append(m.items[:i], m.items[i+1:]...)
+				break
+			}
+		}
+		m.cursor = min(m.cursor, max(len(m.items)-1, 0))
+		m.scroll()
+		m.status = "Deleted " + msg.id
+		return m, m.loadMore()
+
+	case browseStatsMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.stats = msg.stats
+
+	case browseStatusMsg:
+		m.status, m.err = msg.status, msg.err
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m browseModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	entry, selected := m.selected()
+	if m.confirming {
+		m.confirming = false
+		if key == "y" && selected {
+			m.status = "Deleting " + entryID(entry) + "..."
+			return m, deleteBrowseItem(entryID(entry), entryDeleteID(entry))
+		}
+		m.status = "Not deleted"
+		return m, nil
+	}
+
+	m.status, m.err = "", nil
+	switch key {
+	case "q":
+		return m, tea.Quit
+	case "esc":
+		if m.stats == nil {
+			return m, tea.Quit
+		}
+		m.stats = nil
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			m.stats = nil
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+			m.stats = nil
+		}
+	case "tab":
+		kind := entryURL
+		if m.kind == entryURL {
+			kind = entryPaste
+		}
+		width, height := m.width, m.height
+		m = newBrowseModel(kind)
+		m.width, m.height = width, height
+		return m, m.Init()
+	case "o":
+		if selected {
+			return m, openBrowseItem(entryLink(entry))
+		}
+	case "c":
+		if selected {
+			return m, copyBrowseItem(entryLink(entry))
+		}
+	case "d":
+		if selected {
+			m.confirming = true
+		}
+	case "s":
+		if selected {
+			if entry.URL == nil {
+				m.status = "Stats are only available for shortened URLs"
+				return m, nil
+			}
+			return m, fetchBrowseStats(entry.URL.Id)
+		}
+	}
+
+	m.scroll()
+	return m, m.loadMore()
+}
+
+func (m browseModel) selected() (listEntry, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return listEntry{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+// loadMore fetches the next page once the cursor nears the end of the
+// loaded items.
+func (m *browseModel) loadMore() tea.Cmd {
+	if !m.more || m.loading || m.cursor < len(m.items)-browseLookahead {
+		return nil
+	}
+	m.loading = true
+	return fetchBrowsePage(m.kind, m.page+1)
+}
+
+// listHeight is the number of item rows that fit on screen.
+func (m browseModel) listHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	chrome := browseChrome
+	if m.stats != nil {
+		chrome += 6
+	}
+	return max(m.height-chrome, 1)
+}
+
+// scroll keeps the cursor on screen.
+func (m *browseModel) scroll() {
+	rows := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+func (m browseModel) View() string {
+	var b strings.Builder
+
+	title, other := "Your Pastes", "URLs"
+	if m.kind == entryURL {
+		title, other = "Your URLs", "pastes"
+	}
+	b.WriteString(theme.Title.PaddingBottom(0).Render(title) + theme.HelpDesc.Render("tab: show "+other) + "\n\n")
+
+	switch {
+	case len(m.items) == 0 && m.loading:
+		b.WriteString(theme.TableCell.Render("Loading...") + "\n")
+	case len(m.items) == 0 && m.err == nil:
+		b.WriteString(theme.TableCell.Render("Nothing here yet") + "\n")
+	}
+
+	end := min(m.offset+m.listHeight(), len(m.items))
+	for i := m.offset; i < end; i++ {
+		row := browseRow(m.items[i])
+		if m.width > 0 {
+			row = ansi.Truncate(row, m.width-4, "…")
+		}
+		if i == m.cursor {
+			b.WriteString(theme.TableCell.Reverse(true).Render("> "+row) + "\n")
+		} else {
+			b.WriteString(theme.TableCell.Render("  "+row) + "\n")
+		}
+	}
+
+	if m.stats != nil {
+		b.WriteString(renderBrowseStats(m.stats) + "\n")
+	}
+
+	switch {
+	case m.err != nil:
+		b.WriteString(theme.FormatError(m.err.Error()))
+	case m.confirming:
+		entry, _ := m.selected()
+		b.WriteString(theme.FormatWarning(fmt.Sprintf("Delete %s? (y/N)", entryID(entry))))
+	case m.status != "":
+		b.WriteString(theme.FormatSuccess(m.status))
+	case m.loading:
+		b.WriteString(theme.HelpDesc.Render("Loading more..."))
+	}
+	b.WriteString("\n")
+	b.WriteString(theme.HelpDesc.Render("↑/↓ move • o open • c copy • d delete • s stats • q quit"))
+	return b.String()
+}
+
+// browseRow is the one-line summary of an item in the list.
+func browseRow(entry listEntry) string {
+	if entry.Paste != nil {
+		p := entry.Paste
+		return fmt.Sprintf("%-10s %-8s %-16s %s", p.Id, humanize.Bytes(uint64(p.Size)), formatTableTime(&p.CreatedAt), p.Filename)
+	}
+	u := entry.URL
+	return fmt.Sprintf("%-10s %6d clicks  %-16s %s", u.Id, u.Clicks, formatTableTime(&u.CreatedAt), u.OriginalURL)
+}
+
+func renderBrowseStats(stats *api.URLStatsResponse) string {
+	lastClick := "no clicks yet"
+	if stats.LastClick != nil {
+		lastClick = formatTimestamp(*stats.LastClick)
+	}
+	expires := "never"
+	if stats.ExpiresAt != nil {
+		expires = formatTimestamp(*stats.ExpiresAt)
+	}
+
+	lines := []string{
+		theme.FormatKeyValue("Short URL", stats.ShortURL),
+		theme.FormatKeyValue("Clicks", fmt.Sprintf("%d", stats.Clicks)),
+		theme.FormatKeyValue("Last Click", lastClick),
+		theme.FormatKeyValue("Expires", expires),
+	}
+	return theme.InfoBox.Padding(0, 1).MarginTop(0).MarginBottom(0).Render(strings.Join(lines, "\n"))
+}
+
+func entryID(entry listEntry) string {
+	if entry.Paste != nil {
+		return entry.Paste.Id
+	}
+	return entry.URL.Id
+}
+
+// entryDeleteID is the ID to delete an item by, which for pastes can differ
+// from the public one.
+func entryDeleteID(entry listEntry) string {
+	if entry.Paste != nil {
+		return pasteDeleteID(*entry.Paste)
+	}
+	return entry.URL.Id
+}
+
+// entryLink is the public URL of an item: the paste page or the short URL.
+func entryLink(entry listEntry) string {
+	if entry.Paste != nil {
+		return entry.Paste.URL
+	}
+	return entry.URL.ShortURL
+}
+
+func fetchBrowsePage(kind string, page int) tea.Cmd {
+	return func() tea.Msg {
+		msg := browsePageMsg{kind: kind, page: page}
+		opts := api.ListOptions{Page: page, PerPage: browsePageSize}
+
+		var total int
+		if kind == entryPaste {
+			resp, err := client.ListPastes(opts)
+			if err != nil {
+				msg.err = wrapAPIError("error listing pastes", err)
+				return msg
+			}
+			if !resp.Success {
+				msg.err = fmt.Errorf("error listing pastes: %s", resp.Error)
+				return msg
+			}
+			for i := range resp.Data.Items {
+				msg.entries = append(msg.entries, listEntry{Type: entryPaste, Paste: &resp.Data.Items[i]})
+			}
+			total = resp.Data.Total
+		} else {
+			resp, err := client.ListURLs(opts)
+			if err != nil {
+				msg.err = wrapAPIError("error listing URLs", err)
+				return msg
+			}
+			if !resp.Success {
+				msg.err = fmt.Errorf("error listing URLs: %s", resp.Error)
+				return msg
+			}
+			for i := range resp.Data.Items {
+				msg.entries = append(msg.entries, listEntry{Type: entryURL, URL: &resp.Data.Items[i]})
+			}
+			total = resp.Data.Total
+		}
+
+		// The same stopping rules as fetchAll: a short page, reaching Total,
+		// or maxPages.
+		loaded := (page-1)*browsePageSize + len(msg.entries)
+		msg.more = len(msg.entries) == browsePageSize && (total == 0 || loaded < total) && page < maxPages
+		return msg
+	}
+}
+
+func deleteBrowseItem(id, deleteID string) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := client.Delete(deleteID)
+		if err != nil {
+			return browseDeletedMsg{id: id, err: wrapAPIError("error deleting content", err)}
+		}
+		if !resp.Success {
+			return browseDeletedMsg{id: id, err: fmt.Errorf("error deleting content: %s", resp.Error)}
+		}
+		return browseDeletedMsg{id: id}
+	}
+}
+
+func fetchBrowseStats(id string) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := client.GetURLStats(id)
+		if err != nil {
+			return browseStatsMsg{err: wrapAPIError("error getting URL stats", err)}
+		}
+		if !resp.Success {
+			return browseStatsMsg{err: fmt.Errorf("error getting URL stats: %s", resp.Error)}
+		}
+		return browseStatsMsg{stats: resp}
+	}
+}
+
+func openBrowseItem(target string) tea.Cmd {
+	return func() tea.Msg {
+		if err := openBrowser(target); err != nil {
+			return browseStatusMsg{err: fmt.Errorf("could not open browser: %w", err)}
+		}
+		return browseStatusMsg{status: "Opened " + target}
+	}
+}
+
+func copyBrowseItem(text string) tea.Cmd {
+	return func() tea.Msg {
+		if err := writeClipboard(text); err != nil {
+			return browseStatusMsg{err: fmt.Errorf("could not copy to clipboard: %w", err)}
+		}
+		return browseStatusMsg{status: "Copied " + text}
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// sendKey feeds a key press to the model and runs the command it returns,
+// feeding the resulting message back in, as the Bubble Tea runtime would.
+func sendKey(t *testing.T, m browseModel, key string) browseModel {
+	t.Helper()
+	var msg tea.KeyMsg
+	switch key {
+	case "tab":
+		msg = tea.KeyMsg{Type: tea.KeyTab}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	model, cmd := m.Update(msg)
+	return runBrowseCmd(t, model.(browseModel), cmd)
+}
+
+func runBrowseCmd(t *testing.T, m browseModel, cmd tea.Cmd) browseModel {
+	t.Helper()
+	for cmd != nil {
+		msg := cmd()
+		if _, ok := msg.(tea.QuitMsg); ok {
+			return m
+		}
+		var model tea.Model
+		model, cmd = m.Update(msg)
+		m = model.(browseModel)
+	}
+	return m
+}
+
+func TestBrowseModel(t *testing.T) {
+	var requestedPages []int
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/pastes":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			requestedPages = append(requestedPages, page)
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Total = 30
+			for i := (page - 1) * browsePageSize; i < min(page*browsePageSize, 30); i++ {
+				resp.Data.Items = append(resp.Data.Items, api.PasteListItem{
+					Id:        fmt.Sprintf("p%d", i),
+					Filename:  fmt.Sprintf("file%d.txt", i),
+					URL:       fmt.Sprintf("https://0x45.st/p%d", i),
+					DeleteURL: fmt.Sprintf("https://0x45.st/delete/d%d", i),
+					CreatedAt: "2024-01-01T00:00:00Z",
+				})
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/urls":
+			resp := api.ListResponse[api.URLListItem]{Success: true}
+			resp.Data.Items = []api.URLListItem{{Id: "u1", ShortURL: "https://0x45.st/u1", OriginalURL: "https://example.com"}}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/urls/u1/stats":
+			_ = json.NewEncoder(w).Encode(api.URLStatsResponse{Success: true, Id: "u1", ShortURL: "https://0x45.st/u1", Clicks: 42})
+		case strings.HasPrefix(r.URL.Path, "/delete/"):
+			deleted = strings.TrimPrefix(r.URL.Path, "/delete/")
+			_ = json.NewEncoder(w).Encode(api.GenericResponse{Success: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	var copied string
+	oldClipboard := writeClipboard
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	defer func() { writeClipboard = oldClipboard }()
+
+	m := newBrowseModel(entryPaste)
+	m = runBrowseCmd(t, m, m.Init())
+	if len(m.items) != browsePageSize || !m.more {
+		t.Fatalf("Expected one page with more to come, got %d items, more=%v", len(m.items), m.more)
+	}
+	if !strings.Contains(m.View(), "file0.txt") {
+		t.Errorf("Expected the first paste in the view, got %s", m.View())
+	}
+
+	// The next page is only fetched once the cursor nears the end.
+	for i := 0; i < browsePageSize-browseLookahead-1; i++ {
+		m = sendKey(t, m, "down")
+	}
+	if len(requestedPages) != 1 {
+		t.Fatalf("Expected no more pages yet, requested %v", requestedPages)
+	}
+	m = sendKey(t, m, "j")
+	if len(m.items) != 30 || m.more {
+		t.Errorf("Expected all 30 pastes after scrolling, got %d, more=%v", len(m.items), m.more)
+	}
+	if fmt.Sprint(requestedPages) != "[1 2]" {
+		t.Errorf("Expected pages 1 and 2 to be requested, got %v", requestedPages)
+	}
+
+	m = sendKey(t, m, "c")
+	if copied != "https://0x45.st/p20" {
+		t.Errorf("Expected the selected paste URL to be copied, got %q", copied)
+	}
+
+	// Deleting asks first; anything but y leaves the paste alone.
+	m = sendKey(t, m, "d")
+	if !strings.Contains(m.View(), "Delete p20?") {
+		t.Errorf("Expected a confirmation prompt, got %s", m.View())
+	}
+	m = sendKey(t, m, "n")
+	if deleted != "" {
+		t.Errorf("Expected nothing to be deleted, got %q", deleted)
+	}
+	m = sendKey(t, m, "d")
+	m = sendKey(t, m, "y")
+	if deleted != "d20" || len(m.items) != 29 {
+		t.Errorf("Expected p20 to be deleted by its delete ID and removed, got %q with %d items", deleted, len(m.items))
+	}
+
+	m = sendKey(t, m, "tab")
+	if m.kind != entryURL || len(m.items) != 1 || m.cursor != 0 {
+		t.Fatalf("Expected the URL list after tab, got kind %s with %d items", m.kind, len(m.items))
+	}
+	m = sendKey(t, m, "s")
+	if m.stats == nil || m.stats.Clicks != 42 || !strings.Contains(m.View(), "42") {
+		t.Errorf("Expected stats for u1, got %+v", m.stats)
+	}
+}
+
+func TestBrowseHandlerNonInteractive(t *testing.T) {
+	oldCanPrompt := canPrompt
+	canPrompt = func(*cobra.Command) bool { return false }
+	defer func() { canPrompt = oldCanPrompt }()
+
+	cmd := NewBrowseCmd()
+	err := Browse(cmd, nil)
+	var usageErr *UsageError
+	if !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error without a terminal, got %v", err)
+	}
+
+	canPrompt = func(*cobra.Command) bool { return true }
+	if err := Browse(cmd, []string{"everything"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for an invalid type, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// deleteIDsByURL resolves paste and short URLs to the IDs to delete them by.
+// The last element of each URL's path names the paste or short URL, which is
+// looked up among your own; the server only deletes by ID, and a paste's
+// delete URL may name a different one than its public URL.
+func deleteIDsByURL(refs []string) ([]string, error) {
+	wanted := make([]string, len(refs))
+	for i, ref := range refs {
+		u, err := url.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, usageErrorf("--by-url expects a paste or short URL, got %q", ref)
+		}
+		wanted[i] = lastPathElement(u.Path)
+	}
+
+	ids := make([]string, len(refs))
+	unresolved := len(refs)
+
+	pastes, err := fetchAll(client.ListPastes, api.ListOptions{})
+	if err != nil {
+		return nil, wrapAPIError("error looking up pastes", err)
+	}
+	for i, id := range wanted {
+		for _, item := range pastes.Data.Items {
+			if item.Id != id {
+				continue
+			}
+			ids[i] = pasteDeleteID(item)
+			unresolved--
+			break
+		}
+	}
+
+	if unresolved > 0 {
+		urls, err := fetchAll(client.ListURLs, api.ListOptions{})
+		if err != nil {
+			return nil, wrapAPIError("error looking up URLs", err)
+		}
+		for i, id := range wanted {
+			if ids[i] != "" {
+				continue
+			}
+			for _, item := range urls.Data.Items {
+				if u, err := url.Parse(item.ShortURL); item.Id == id || (err == nil && lastPathElement(u.Path) == id) {
+					ids[i] = item.Id
+					break
+				}
+			}
+		}
+	}
+
+	for i, id := range ids {
+		if id == "" {
+			return nil, notFoundErrorf("%s isn't one of your pastes or short URLs; deleting someone else's needs the ID from their delete URL", refs[i])
+		}
+	}
+	return ids, nil
+}
+
+// pasteDeleteID returns the ID to delete a paste by, which its delete URL
+// names, falling back on its public ID.
+func pasteDeleteID(item api.PasteListItem) string {
+	if u, err := url.Parse(item.DeleteURL); err == nil && item.DeleteURL != "" {
+		return lastPathElement(u.Path)
+	}
+	return item.Id
+}
+
+// findPasteDeleteID looks up one of your pastes by its public ID and returns
+// the ID to delete it by.
+func findPasteDeleteID(id string) (string, error) {
+	pastes, err := fetchAll(client.ListPastes, api.ListOptions{})
+	if err != nil {
+		return "", wrapAPIError("error looking up pastes", err)
+	}
+	for _, item := range pastes.Data.Items {
+		if item.Id == id {
+			return pasteDeleteID(item), nil
+		}
+	}
+	return "", notFoundErrorf("%s isn't one of your pastes", id)
+}
+
+// lastPathElement returns the final element of a URL path, which is where
+// 0x45 puts IDs.
+func lastPathElement(p string) string {
+	return path.Base(strings.TrimSuffix(p, "/"))
+}
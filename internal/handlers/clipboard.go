@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/logging"
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+// writeClipboard is swapped out in tests.
+var writeClipboard = clipboard.WriteAll
+
+// shouldCopy reports whether the result URL should be copied, falling back to
+// the copy_on_upload config value when --copy wasn't given explicitly.
+func shouldCopy(cmd *cobra.Command) (bool, error) {
+	if !cmd.Flags().Changed("copy") {
+		return viper.GetBool("copy_on_upload"), nil
+	}
+	return cmd.Flags().GetBool("copy")
+}
+
+// copyToClipboard copies text to the system clipboard, printing a warning
+// instead of failing when no clipboard is available.
+func copyToClipboard(cmd *cobra.Command, text string) {
+	if err := writeClipboard(text); err != nil {
+		logging.Warnf("Could not copy to clipboard: %v", err)
+		return
+	}
+	if !jsonOutput() && !quietOutput() {
+		fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatSuccess("Copied to clipboard"))
+	}
+}
@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/theme"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigDir returns the directory holding the config file and other local
+// state: $XDG_CONFIG_HOME/0x45, or ~/.config/0x45 when XDG_CONFIG_HOME is
+// unset. As the XDG spec requires, a relative XDG_CONFIG_HOME is ignored.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "0x45"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "0x45"), nil
+}
+
+// DefaultConfigFile returns the config file used when --config isn't given.
+func DefaultConfigFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// legacyConfigFile returns the config location used by earlier releases.
+func legacyConfigFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".0x45.yaml"), nil
+}
+
+// legacyConfigNote is added to the top of a legacy config file once it has
+// been migrated, so anyone opening it knows it is no longer read.
+const legacyConfigNote = "# Deprecated: 0x45 no longer reads this file. Settings are read from %s instead.\n"
+
+// MigrateLegacyConfig copies a config file from the legacy ~/.0x45.yaml to
+// the default location, returning the paths involved when it did. Nothing
+// happens if there is no legacy file or the default one already exists. The
+// legacy file is kept, with a note saying where its settings went.
+func MigrateLegacyConfig() (from, to string, err error) {
+	legacy, err := legacyConfigFile()
+	if err != nil {
+		return "", "", err
+	}
+	target, err := DefaultConfigFile()
+	if err != nil {
+		return "", "", err
+	}
+
+	if _, err := os.Stat(target); err == nil || !os.IsNotExist(err) {
+		return "", "", nil
+	}
+	data, err := os.ReadFile(legacy)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("error reading %s: %w", legacy, err)
+	}
+	// The note from an earlier migration isn't carried over.
+	if line, rest, ok := bytes.Cut(data, []byte("\n")); ok && isLegacyConfigNote(line) {
+		data = rest
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return "", "", fmt.Errorf("error creating config directory: %w", err)
+	}
+	// O_EXCL keeps a concurrent run from having its config overwritten.
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("error creating %s: %w", target, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(target)
+		return "", "", fmt.Errorf("error writing %s: %w", target, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(target)
+		return "", "", fmt.Errorf("error writing %s: %w", target, err)
+	}
+	return legacy, target, markLegacyConfig(legacy, target)
+}
+
+func isLegacyConfigNote(line []byte) bool {
+	return bytes.HasPrefix(line, []byte("# Deprecated: 0x45 no longer reads this file."))
+}
+
+// markLegacyConfig adds the deprecation note to the legacy config file at
+// path, unless it already has one.
+func markLegacyConfig(path, target string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if line, _, _ := bytes.Cut(data, []byte("\n")); isLegacyConfigNote(line) {
+		return nil
+	}
+
+	note := fmt.Sprintf(legacyConfigNote, target)
+	if err := os.WriteFile(path, append([]byte(note), data...), 0600); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// configFilePath returns the config file in use, or the default location a
+// new config file would be written to.
+func configFilePath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	return DefaultConfigFile()
+}
+
+// writeConfigValue stores key in the config file. Only the file's own
+// contents are rewritten, so defaults and flag values aren't persisted.
+func writeConfigValue(key string, value any) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(theme.FormatError("Could not read config file: %v"), err)
+	}
+
+	file.Set(key, value)
+	viper.Set(key, value)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(theme.FormatError("Could not create config directory: %v"), err)
+	}
+	if err := file.WriteConfigAs(path); err != nil {
+		return fmt.Errorf(theme.FormatError("Could not write config file: %v"), err)
+	}
+	return nil
+}
+
+// ConfigKeys are the settings the CLI reads from the config file, either
+// directly or as the config equivalent of a global flag. Each can also be
+// set with an OX45_ environment variable, e.g. OX45_API_KEY.
+var ConfigKeys = []string{
+	"api_key", "api_url", "auth_header", "copy_on_upload", "date_format",
+	"default_expiry", "default_stdin_filename", "disable_proxy", "filename_via",
+	"follow_redirects", "headers", "http_proxy", "insecure_allow_http", "json",
+	"json_compact", "json_pretty", "log_level", "max_expiry_days",
+	"max_expiry_days_anon", "max_upload_size", "no_proxy", "profile", "profiles",
+	"quiet", "relative", "request_timeout", "theme", "verbose",
+}
+
+// configValueTypes are the --type values config set accepts.
+var configValueTypes = []string{"string", "bool", "int"}
+
+// coerceConfigValue converts a config set value to kind, so that booleans
+// and numbers are written to the YAML file unquoted.
+func coerceConfigValue(value, kind string) (any, error) {
+	switch kind {
+	case "string":
+		return value, nil
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, usageErrorf("invalid bool %q: use true or false", value)
+		}
+		return b, nil
+	case "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, usageErrorf("invalid int %q: use a whole number", value)
+		}
+		return n, nil
+	default:
+		return nil, checkConfigType(kind)
+	}
+}
+
+// checkConfigType rejects a --type that isn't in configValueTypes.
+func checkConfigType(kind string) error {
+	if slices.Contains(configValueTypes, kind) {
+		return nil
+	}
+	return usageErrorf("invalid type %q: must be one of %s", kind, strings.Join(configValueTypes, ", "))
+}
+
+// editorCommand returns the user's preferred editor split into its arguments.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(env)); len(editor) > 0 {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func newConfigPathCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the location of the config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configFilePath()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+			return nil
+		},
+	}
+}
+
+func newConfigEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $EDITOR",
+		Args:  cobra.NoArgs,
+		RunE:  ConfigEdit,
+	}
+}
+
+func ConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("could not create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			return fmt.Errorf("could not create config file: %w", err)
+		}
+	}
+
+	editor := editorCommand()
+	c := exec.Command(editor[0], append(editor[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor exited with error: %w", err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+
+	var parsed map[string]any
+	if err := yaml.Unmarshal(edited, &parsed); err != nil {
+		if err := os.WriteFile(path, original, 0600); err != nil {
+			return fmt.Errorf("could not restore config file: %w", err)
+		}
+		return fmt.Errorf("config file is not valid YAML, changes were reverted: %w", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("Saved %s", path)))
+	return nil
+}
+
+func newConfigMigrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Move a legacy ~/.0x45.yaml to the current config location",
+		Long: `Copy the config file used by older releases, ~/.0x45.yaml, to the current
+location and mark the old file as no longer read. Nothing is changed if
+there is no old file or the config has already been migrated.`,
+		Args: cobra.NoArgs,
+		RunE: ConfigMigrate,
+	}
+}
+
+func ConfigMigrate(cmd *cobra.Command, args []string) error {
+	legacy, err := legacyConfigFile()
+	if err != nil {
+		return err
+	}
+	target, err := DefaultConfigFile()
+	if err != nil {
+		return err
+	}
+
+	from, to, err := MigrateLegacyConfig()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if to != "" {
+		fmt.Fprintln(out, theme.FormatSuccess(fmt.Sprintf("Copied %s to %s", from, to)))
+		fmt.Fprintf(out, "%s is no longer read and can be removed.\n", from)
+		return nil
+	}
+
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		fmt.Fprintf(out, "No legacy config file at %s, nothing to migrate.\n", legacy)
+		return nil
+	}
+	// The config was already migrated, usually on startup. Make sure the old
+	// file says so.
+	if err := markLegacyConfig(legacy, target); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, theme.FormatSuccess(fmt.Sprintf("Already migrated to %s", target)))
+	return nil
+}
+
+func newConfigProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named profiles for different servers or accounts",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles defined in the config file",
+		Args:  cobra.NoArgs,
+		RunE:  ConfigProfileList,
+	}
+
+	useCmd := &cobra.Command{
+		Use:   "use [name]",
+		Short: "Make a profile the default",
+		Args:  cobra.ExactArgs(1),
+		RunE:  ConfigProfileUse,
+	}
+
+	cmd.AddCommand(listCmd, useCmd)
+	return cmd
+}
+
+// profileNames returns the profiles defined under "profiles", sorted.
+func profileNames() []string {
+	profiles := viper.GetStringMap("profiles")
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ConfigProfileList(cmd *cobra.Command, args []string) error {
+	names := profileNames()
+	if len(names) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatWarning("No profiles defined"))
+		return nil
+	}
+
+	active := viper.GetString("profile")
+	for _, name := range names {
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		apiURL := viper.GetString("profiles." + name + ".api_url")
+		if apiURL == "" {
+			apiURL = viper.GetString("api_url")
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s%s\n", marker, theme.FormatKeyValue(name, apiURL))
+	}
+	return nil
+}
+
+func ConfigProfileUse(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	if !viper.IsSet("profiles." + name) {
+		return usageErrorf("unknown profile: %s", args[0])
+	}
+
+	if err := writeConfigValue("profile", name); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("Now using profile '%s'", name)))
+	return nil
+}
+
+func newConfigListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all config values",
+		Args:  cobra.NoArgs,
+		RunE:  ConfigList,
+	}
+
+	cmd.Flags().Bool("show-secrets", false, "Show API keys and other secrets in full")
+	return cmd
+}
+
+func ConfigList(cmd *cobra.Command, args []string) error {
+	showSecrets, err := cmd.Flags().GetBool("show-secrets")
+	if err != nil {
+		return err
+	}
+
+	keys := viper.AllKeys()
+	if len(keys) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatWarning("No config values set"))
+		return nil
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// Settings bound to an environment variable that isn't set have no
+		// value at all.
+		raw := viper.Get(key)
+		if raw == nil {
+			continue
+		}
+		value := fmt.Sprint(raw)
+		if !showSecrets {
+			value = redactConfigValue(key, raw)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue(key, value))
+	}
+	return nil
+}
+
+// isSecretKey reports whether the config key holds a credential that
+// shouldn't be printed by default, including keys nested under profiles.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	return key == "api_key" || strings.HasSuffix(key, ".api_key") ||
+		strings.Contains(key, "token") || strings.Contains(key, "secret")
+}
+
+// redactConfigValue formats the value of a config key with its secrets
+// masked: credentials in full, the values but not the names of custom
+// headers, which often carry auth tokens, and the password in a proxy URL.
+func redactConfigValue(key string, raw any) string {
+	value := fmt.Sprint(raw)
+	name := strings.ToLower(key)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	switch {
+	case name == "headers":
+		return fmt.Sprint(redactHeaders(raw))
+	case name == "http_proxy":
+		if u, err := url.Parse(value); err == nil {
+			return u.Redacted()
+		}
+		return redactSecret(value)
+	case isSecretKey(key):
+		return redactSecret(value)
+	}
+	return value
+}
+
+// redactHeaders masks the value of each "Key: Value" header in raw, a list
+// from the config file or one header per line from OX45_HEADERS.
+func redactHeaders(raw any) []string {
+	var headers []string
+	switch v := raw.(type) {
+	case string:
+		headers = strings.Split(strings.TrimSpace(v), "\n")
+	case []string:
+		headers = v
+	case []any:
+		for _, h := range v {
+			headers = append(headers, fmt.Sprint(h))
+		}
+	default:
+		return []string{redactSecret(fmt.Sprint(raw))}
+	}
+
+	redacted := make([]string, len(headers))
+	for i, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			redacted[i] = redactSecret(strings.TrimSpace(header))
+			continue
+		}
+		redacted[i] = strings.TrimSpace(name) + ": " + redactSecret(strings.TrimSpace(value))
+	}
+	return redacted
+}
+
+// redactSecret masks all but the last four characters of value. Short values
+// are masked entirely.
+func redactSecret(value string) string {
+	if len(value) <= 4 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+}
+
+func newConfigThemeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "theme",
+		Short: "Show the colors used for output",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the current color of each style",
+		Args:  cobra.NoArgs,
+		RunE:  ConfigThemeList,
+	}
+
+	cmd.AddCommand(listCmd)
+	return cmd
+}
+
+func ConfigThemeList(cmd *cobra.Command, args []string) error {
+	for _, sc := range theme.Colors() {
+		swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(sc.Color)).Render("■■■")
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.FormatKeyValue(sc.Name, sc.Color), swatch)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// canPrompt reports whether the user can answer a prompt; swapped out in
+// tests.
+var canPrompt = stdinIsTerminal
+
+// confirm asks a yes/no question on stderr and reads the answer from the
+// command's input. Anything but "y" or "yes" counts as no.
+func confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(cmd.ErrOrStderr())
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/viper"
+)
+
+// relativeDates is the date_format value that shows times relative to now,
+// e.g. "3 days ago".
+const relativeDates = "relative"
+
+// formatTime renders t with the date_format setting, which --date-format
+// overrides, or with layout when neither is set. --relative is shorthand
+// for date_format relative.
+func formatTime(t time.Time, layout string) string {
+	format := viper.GetString("date_format")
+	if viper.GetBool("relative") {
+		format = relativeDates
+	}
+	switch format {
+	case "":
+	case relativeDates:
+		return humanize.Time(t)
+	default:
+		layout = format
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// cachedUpload is what --dedupe remembers about an earlier upload.
+type cachedUpload struct {
+	URL        string `json:"url"`
+	DeleteURL  string `json:"delete_url,omitempty"`
+	Filename   string `json:"filename"`
+	UploadedAt string `json:"uploaded_at"`
+}
+
+// uploadCachePath is the file mapping content hashes to earlier uploads.
+func uploadCachePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "uploads.json"), nil
+}
+
+func loadUploadCache() (map[string]cachedUpload, error) {
+	cache := make(map[string]cachedUpload)
+
+	cachePath, err := uploadCachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(cachePath)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading upload cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("error reading upload cache: %w", err)
+	}
+	return cache, nil
+}
+
+func saveUploadCache(cache map[string]cachedUpload) error {
+	cachePath, err := uploadCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding upload cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return fmt.Errorf("error creating upload cache directory: %w", err)
+	}
+	if err := os.WriteFile(cachePath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("error writing upload cache: %w", err)
+	}
+	return nil
+}
+
+// hashReader returns the hex SHA-256 of everything read from r.
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	sum, err := hashReader(f)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	return sum, nil
+}
+
+// uploadCacheKey is where an upload of content with the given hash is
+// remembered. Options that change who can see the paste and for how long
+// are part of the key, so an upload is only reused for the same ones; plain
+// uploads are keyed by the hash alone.
+func uploadCacheKey(hash string, opts api.UploadOptions) string {
+	options := url.Values{}
+	if opts.Private {
+		options.Set("private", "true")
+	}
+	if opts.Expires != "" {
+		options.Set("expires", opts.Expires)
+	}
+	if opts.Title != "" {
+		options.Set("title", opts.Title)
+	}
+	// The password itself is never written to disk.
+	if opts.Password != "" {
+		options.Set("password", "set")
+	}
+	if len(options) == 0 {
+		return hash
+	}
+	return hash + "?" + options.Encode()
+}
+
+// findUpload looks up content by hash and upload options, returning the
+// earlier upload only if the server still has it. Entries for pastes that
+// have since expired or been deleted are dropped from the cache.
+func findUpload(hash string, opts api.UploadOptions) (*cachedUpload, error) {
+	cache, err := loadUploadCache()
+	if err != nil {
+		return nil, err
+	}
+	key := uploadCacheKey(hash, opts)
+	cached, ok := cache[key]
+	if !ok {
+		return nil, nil
+	}
+
+	exists, err := client.Exists(path.Base(cached.URL), false)
+	if err != nil {
+		return nil, wrapAPIError("error checking earlier upload", err)
+	}
+	if !exists {
+		delete(cache, key)
+		return nil, saveUploadCache(cache)
+	}
+	return &cached, nil
+}
+
+// rememberUpload records a successful upload for --dedupe when hash is set.
+// The upload itself has already succeeded, so failing to record it is only
+// a warning.
+func rememberUpload(hash, filename string, opts api.UploadOptions, resp *api.UploadResponse) {
+	if hash == "" || !resp.Success {
+		return
+	}
+	if err := recordUpload(uploadCacheKey(hash, opts), filename, resp); err != nil {
+		logging.Warnf("Could not remember upload: %v", err)
+	}
+}
+
+// recordUpload remembers a successful upload under the given cache key.
+func recordUpload(key, filename string, resp *api.UploadResponse) error {
+	cache, err := loadUploadCache()
+	if err != nil {
+		return err
+	}
+	cache[key] = cachedUpload{
+		URL:        resp.URL,
+		DeleteURL:  resp.DeleteURL,
+		Filename:   filename,
+		UploadedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	return saveUploadCache(cache)
+}
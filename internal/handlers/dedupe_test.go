@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerDedupe(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	uploads := 0
+	pasteExists := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			if !pasteExists {
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		}
+		uploads++
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("same content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := func(flags map[string]string, args ...string) string {
+		t.Helper()
+		cmd := NewUploadCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		_ = cmd.Flags().Set("dedupe", "true")
+		for name, value := range flags {
+			_ = cmd.Flags().Set(name, value)
+		}
+		if err := Upload(cmd, args); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	upload(nil, file)
+	output := upload(nil, file)
+	if uploads != 1 {
+		t.Errorf("Expected the second upload to be deduplicated, got %d uploads", uploads)
+	}
+	if !strings.Contains(output, "Already uploaded") || !strings.Contains(output, "https://0x45.st/abc123") {
+		t.Errorf("Expected the cached URL, got %s", output)
+	}
+
+	upload(map[string]string{"content": "same content"})
+	if uploads != 1 {
+		t.Errorf("Expected --content with the same text to be deduplicated, got %d uploads", uploads)
+	}
+
+	// The earlier upload was public, so it can't stand in for a private one.
+	upload(map[string]string{"private": "true"}, file)
+	if uploads != 2 {
+		t.Errorf("Expected --private to upload again, got %d uploads", uploads)
+	}
+	upload(map[string]string{"private": "true"}, file)
+	upload(nil, file)
+	if uploads != 2 {
+		t.Errorf("Expected uploads with the same options to be deduplicated, got %d uploads", uploads)
+	}
+
+	upload(map[string]string{"force": "true"}, file)
+	if uploads != 3 {
+		t.Errorf("Expected --force to upload again, got %d uploads", uploads)
+	}
+
+	pasteExists = false
+	upload(nil, file)
+	if uploads != 4 {
+		t.Errorf("Expected a deleted paste to be uploaded again, got %d uploads", uploads)
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("dedupe", "true")
+	cmd.SetIn(strings.NewReader("piped"))
+	var usageErr *UsageError
+	if err := Upload(cmd, []string{"-"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected --dedupe with stdin to be a usage error, got %v", err)
+	}
+}
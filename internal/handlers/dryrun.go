@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+// dryRunRequest describes a request that --dry-run skipped sending.
+type dryRunRequest struct {
+	DryRun   bool   `json:"dry_run"`
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	Filename string `json:"filename,omitempty"`
+	Size     *int64 `json:"size,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Private  bool   `json:"private"`
+	Expires  string `json:"expires,omitempty"`
+	Title    string `json:"title,omitempty"`
+	// Password reports whether the paste would be password-protected; the
+	// password itself is never shown.
+	Password bool `json:"password_protected,omitempty"`
+	Encrypt  bool `json:"encrypted,omitempty"`
+}
+
+func printDryRun(cmd *cobra.Command, req dryRunRequest) error {
+	req.DryRun = true
+	if jsonOutput() {
+		return printJSON(cmd, req)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, theme.Title.Render("Dry run: nothing was sent"))
+	fmt.Fprintln(out, theme.FormatKeyValue("Request", req.Method+" "+req.Endpoint))
+	if req.Filename != "" {
+		fmt.Fprintln(out, theme.FormatKeyValue("Filename", req.Filename))
+	}
+	if req.Title != "" {
+		fmt.Fprintln(out, theme.FormatKeyValue("Title", req.Title))
+	}
+	if req.Size != nil {
+		fmt.Fprintln(out, theme.FormatKeyValue("Size", humanize.Bytes(uint64(*req.Size))))
+	}
+	if req.MimeType != "" {
+		fmt.Fprintln(out, theme.FormatKeyValue("Content Type", req.MimeType))
+	}
+	if req.URL != "" {
+		fmt.Fprintf(out, "%s %s\n", theme.ListItemKey.Render("URL:"), theme.FormatURL(req.URL))
+	}
+	fmt.Fprintln(out, theme.FormatKeyValue("Private", strconv.FormatBool(req.Private)))
+	if req.Encrypt {
+		fmt.Fprintln(out, theme.FormatKeyValue("Encrypted", "true (key generated at upload)"))
+	}
+	if req.Password {
+		fmt.Fprintln(out, theme.FormatKeyValue("Password", "set (not shown)"))
+	}
+
+	expires := req.Expires
+	if expires == "" {
+		expires = "server default"
+	}
+	fmt.Fprintln(out, theme.FormatKeyValue("Expires", expires))
+	return nil
+}
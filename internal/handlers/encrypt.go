@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// Encrypted pastes are the 12-byte nonce followed by the AES-256-GCM
+// ciphertext of the content. The 32-byte key never reaches the server: it
+// travels in the URL fragment as key=<unpadded base64url>.
+const (
+	encryptionKeySize = 32
+	keyFragmentPrefix = "key="
+	encryptedMimeType = "application/octet-stream"
+)
+
+// sealContent encrypts plaintext under a new random key.
+func sealContent(plaintext []byte) (sealed, key []byte, err error) {
+	key = make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, fmt.Errorf("error generating key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), key, nil
+}
+
+// openContent decrypts content sealed by sealContent.
+func openContent(sealed, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("error decrypting paste: content is too short to be encrypted")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("error decrypting paste: wrong key or corrupted content")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealUpload reads all of r and returns it encrypted, with its size and the
+// key it was sealed with. AES-GCM authenticates the content as a whole, so
+// encrypted uploads are buffered rather than streamed.
+func sealUpload(r io.Reader) (io.Reader, int64, []byte, error) {
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error reading content: %w", err)
+	}
+	sealed, key, err := sealContent(plaintext)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return bytes.NewReader(sealed), int64(len(sealed)), key, nil
+}
+
+// addKeyFragment appends key to the URL of a successful encrypted upload.
+func addKeyFragment(resp *api.UploadResponse, key []byte) {
+	if key != nil && resp.Success && resp.URL != "" {
+		resp.URL = withKeyFragment(resp.URL, key)
+	}
+}
+
+// withKeyFragment adds key to pasteURL as its fragment.
+func withKeyFragment(pasteURL string, key []byte) string {
+	return pasteURL + "#" + keyFragmentPrefix + base64.RawURLEncoding.EncodeToString(key)
+}
+
+// parseKey decodes a key as it appears in a URL fragment, with or without
+// the key= prefix.
+func parseKey(s string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, keyFragmentPrefix))
+	if err != nil || len(key) != encryptionKeySize {
+		return nil, usageErrorf("invalid decryption key: expected %d bytes of base64url", encryptionKeySize)
+	}
+	return key, nil
+}
+
+// splitPasteRef accepts a paste ID or URL and returns the ID along with the
+// decryption key from the URL's fragment, if it has one.
+func splitPasteRef(ref string) (id, key string) {
+	if !strings.Contains(ref, "://") {
+		return ref, ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref, ""
+	}
+	if strings.HasPrefix(u.Fragment, keyFragmentPrefix) {
+		key = u.Fragment
+	}
+	return lastPathElement(u.Path), key
+}
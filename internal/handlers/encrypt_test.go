@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestSealContent(t *testing.T) {
+	plaintext := []byte("attack at dawn")
+	sealed, key, err := sealContent(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != encryptionKeySize || bytes.Contains(sealed, plaintext) {
+		t.Fatalf("Expected a %d-byte key and opaque content, got %d bytes and %q", encryptionKeySize, len(key), sealed)
+	}
+
+	opened, err := openContent(sealed, key)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Errorf("Expected the plaintext back, got %q, %v", opened, err)
+	}
+
+	_, otherKey, _ := sealContent(nil)
+	if _, err := openContent(sealed, otherKey); err == nil {
+		t.Error("Expected the wrong key to fail")
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := openContent(sealed, key); err == nil {
+		t.Error("Expected tampered content to fail")
+	}
+	if _, err := openContent([]byte("short"), key); err == nil {
+		t.Error("Expected truncated content to fail")
+	}
+}
+
+func TestSplitPasteRef(t *testing.T) {
+	tests := []struct {
+		ref, id, key string
+	}{
+		{"abc123", "abc123", ""},
+		{"https://0x45.st/abc123", "abc123", ""},
+		{"https://0x45.st/abc123#key=c2VjcmV0", "abc123", "key=c2VjcmV0"},
+		{"https://0x45.st/abc123/#top", "abc123", ""},
+	}
+	for _, tt := range tests {
+		id, key := splitPasteRef(tt.ref)
+		if id != tt.id || key != tt.key {
+			t.Errorf("splitPasteRef(%q) = %q, %q; want %q, %q", tt.ref, id, key, tt.id, tt.key)
+		}
+	}
+
+	var usageErr *UsageError
+	if _, err := parseKey("key=tooshort"); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for a short key, got %v", err)
+	}
+}
+
+func TestUploadAndGetEncrypted(t *testing.T) {
+	var stored []byte
+	var contentType string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload":
+			stored, _ = io.ReadAll(r.Body)
+			contentType = r.Header.Get("Content-Type")
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: server.URL + "/abc123"})
+		case "/abc123/raw":
+			_, _ = w.Write(stored)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("content", "top secret")
+	_ = cmd.Flags().Set("encrypt", "true")
+	_ = cmd.Flags().Set("field", "url")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, []byte("top secret")) || contentType != encryptedMimeType {
+		t.Fatalf("Expected only ciphertext to reach the server, got %q as %s", stored, contentType)
+	}
+	pasteURL := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(pasteURL, server.URL+"/abc123#key=") {
+		t.Fatalf("Expected the key in the URL fragment, got %q", pasteURL)
+	}
+
+	getCmd := NewGetCmd()
+	buf.Reset()
+	getCmd.SetOut(&buf)
+	if err := Get(getCmd, []string{pasteURL}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "top secret" {
+		t.Errorf("Expected the decrypted content, got %q", buf.String())
+	}
+
+	// The key can also be given separately from the ID.
+	_, key, _ := strings.Cut(pasteURL, "#key=")
+	getCmd = NewGetCmd()
+	buf.Reset()
+	getCmd.SetOut(&buf)
+	_ = getCmd.Flags().Set("decrypt", key)
+	if err := Get(getCmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "top secret" {
+		t.Errorf("Expected the decrypted content with --decrypt, got %q", buf.String())
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("content", "x")
+	_ = cmd.Flags().Set("encrypt", "true")
+	_ = cmd.Flags().Set("mime", "text/plain")
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected --mime with --encrypt to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// wrapAPIError prefixes err with action, adding a hint for well-known API
+// failures such as an invalid key.
+func wrapAPIError(action string, err error) error {
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%s: API key invalid or missing: %w", action, err)
+		case http.StatusForbidden:
+			return fmt.Errorf("%s: access denied: %w", action, err)
+		case http.StatusNotFound:
+			return fmt.Errorf("%s: not found: %w", action, err)
+		}
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// UsageError marks an error caused by invalid arguments, flags or input
+// rather than by a failed request.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
+
+func usageErrorf(format string, args ...any) error {
+	return &UsageError{Err: fmt.Errorf(format, args...)}
+}
+
+// notFoundError reports a missing paste or URL with a friendlier message
+// while still matching api.ErrNotFound.
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string { return e.msg }
+
+func (e *notFoundError) Unwrap() error { return api.ErrNotFound }
+
+func notFoundErrorf(format string, args ...any) error {
+	return &notFoundError{msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+)
+
+// noExpiry is the expiry sent to ask for content that never expires, which
+// the server only allows with an API key.
+const noExpiry = "never"
+
+// expiryUnits matches the day and week units time.ParseDuration lacks.
+var expiryUnits = regexp.MustCompile(`(\d+(?:\.\d+)?)([dw])`)
+
+// parseExpiry validates a user supplied expiry duration. In addition to the
+// units understood by time.ParseDuration it accepts "d" (days) and "w"
+// (weeks), e.g. "7d" or "2w".
+func parseExpiry(value string) (time.Duration, error) {
+	expanded := expiryUnits.ReplaceAllStringFunc(value, func(m string) string {
+		parts := expiryUnits.FindStringSubmatch(m)
+		n, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return m
+		}
+		hours := n * 24
+		if parts[2] == "w" {
+			hours = n * 24 * 7
+		}
+		return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+	})
+
+	d, err := time.ParseDuration(expanded)
+	if err != nil || d <= 0 {
+		return 0, usageErrorf("invalid expiry duration: %s", value)
+	}
+	return d, nil
+}
+
+// formatExpiry renders d in a form time.ParseDuration understands, preferring
+// whole hours, e.g. "168h" for a week.
+func formatExpiry(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return d.String()
+}
+
+// timeLayouts are the absolute time formats accepted by --expires-at and
+// other flags taking a date. Times without a zone are taken as local time.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseTime parses value in any of timeLayouts.
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseExpiresAt parses an absolute expiry time and returns how far it is
+// from now.
+func parseExpiresAt(value string, now time.Time) (time.Duration, error) {
+	t, ok := parseTime(value)
+	if !ok {
+		return 0, usageErrorf("invalid expiry time: %s (use YYYY-MM-DD or RFC3339)", value)
+	}
+	d := t.Sub(now).Truncate(time.Second)
+	if d <= 0 {
+		return 0, usageErrorf("expiry time is in the past: %s", value)
+	}
+	return d, nil
+}
+
+// isNoExpiry reports whether an --expires value asks for no expiry at all.
+func isNoExpiry(value string) bool {
+	return strings.EqualFold(value, "none") || strings.EqualFold(value, noExpiry)
+}
+
+// resolveExpiry reads the mutually exclusive --expires and --expires-at
+// flags and returns the expiry to send to the API, or "" if neither is set.
+// "--expires none" requests content that never expires.
+func resolveExpiry(cmd *cobra.Command) (string, error) {
+	expires, err := cmd.Flags().GetString("expires")
+	if err != nil {
+		return "", err
+	}
+
+	if isNoExpiry(expires) {
+		if cmd.Flags().Changed("expires-at") {
+			return "", usageErrorf("--expires and --expires-at cannot be used together")
+		}
+		apiKey, err := client.APIKey()
+		if err != nil {
+			return "", err
+		}
+		if apiKey == "" {
+			return "", usageErrorf("content without an expiry requires an API key; anonymous uploads expire after at most %d days", client.MaxExpiryForKey(false)/(24*time.Hour))
+		}
+		return noExpiry, nil
+	}
+
+	expiresAt, err := cmd.Flags().GetString("expires-at")
+	if err != nil {
+		return "", err
+	}
+
+	var d time.Duration
+	switch {
+	case expires != "" && expiresAt != "":
+		return "", usageErrorf("--expires and --expires-at cannot be used together")
+	case expires != "":
+		d, err = parseExpiry(expires)
+	case expiresAt != "":
+		d, err = parseExpiresAt(expiresAt, time.Now())
+	default:
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	apiKey, err := client.APIKey()
+	if err != nil {
+		return "", err
+	}
+	if limit := client.MaxExpiryForKey(apiKey != ""); d > limit {
+		return "", usageErrorf("expiry exceeds the maximum of %d days", limit/(24*time.Hour))
+	}
+
+	return formatExpiry(d), nil
+}
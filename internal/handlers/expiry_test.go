@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "24h", want: 24 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "7d", want: 7 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "1w2d12h", want: 9*24*time.Hour + 12*time.Hour},
+		{input: "1.5d", want: 36 * time.Hour},
+		{input: "", wantErr: true},
+		{input: "soon", wantErr: true},
+		{input: "7x", wantErr: true},
+		{input: "-1d", wantErr: true},
+		{input: "0h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpiry(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseExpiry(%q): expected error, got %s", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseExpiry(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseExpiry(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	if got := formatExpiry(7 * 24 * time.Hour); got != "168h" {
+		t.Errorf("Expected 168h, got %s", got)
+	}
+	if got := formatExpiry(90 * time.Minute); got != "1h30m0s" {
+		t.Errorf("Expected 1h30m0s, got %s", got)
+	}
+}
+
+func TestParseExpiresAt(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "2025-06-02", want: 12 * time.Hour},
+		{input: "2025-06-01T18:30", want: 6*time.Hour + 30*time.Minute},
+		{input: now.Add(48 * time.Hour).Format(time.RFC3339), want: 48 * time.Hour},
+		{input: "2025-05-31", wantErr: true},
+		{input: "2025-06-01T12:00:00", wantErr: true},
+		{input: "next tuesday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpiresAt(tt.input, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseExpiresAt(%q): expected error, got %s", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseExpiresAt(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseExpiresAt(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestResolveExpiry(t *testing.T) {
+	viper.Set("api_key", "")
+	defer viper.Set("api_key", "test-key")
+
+	cmd := NewShortenCmd()
+	_ = cmd.Flags().Set("expires", "7d")
+	_ = cmd.Flags().Set("expires-at", "2099-01-01")
+	if _, err := resolveExpiry(cmd); err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("Expected mutually exclusive error, got %v", err)
+	}
+
+	cmd = NewShortenCmd()
+	_ = cmd.Flags().Set("expires-at", time.Now().AddDate(1, 0, 0).Format("2006-01-02"))
+	if _, err := resolveExpiry(cmd); err == nil || !strings.Contains(err.Error(), "maximum of 128 days") {
+		t.Errorf("Expected anonymous max expiry error, got %v", err)
+	}
+
+	viper.Set("api_key", "test-key")
+	got, err := resolveExpiry(cmd)
+	if err != nil {
+		t.Fatalf("Expected a year to be allowed with an API key, got %v", err)
+	}
+	if _, err := time.ParseDuration(got); err != nil {
+		t.Errorf("Expected a duration the API understands, got %q", got)
+	}
+}
+
+func TestResolveExpiryLimits(t *testing.T) {
+	defer viper.Set("api_key", "test-key")
+	defer viper.Set("max_expiry_days", nil)
+	defer viper.Set("max_expiry_days_anon", nil)
+
+	tests := []struct {
+		key     string
+		limit   string
+		expires string
+		wantErr bool
+	}{
+		{key: "", expires: "128d"},
+		{key: "", expires: "3072h1s", wantErr: true},
+		{key: "test-key", expires: "730d"},
+		{key: "test-key", expires: "731d", wantErr: true},
+		{key: "", limit: "7", expires: "7d"},
+		{key: "", limit: "7", expires: "8d", wantErr: true},
+		{key: "test-key", limit: "1000", expires: "1000d"},
+		{key: "test-key", limit: "1000", expires: "1001d", wantErr: true},
+		{key: "test-key", limit: "0", expires: "730d"},
+	}
+	for _, tt := range tests {
+		viper.Set("api_key", tt.key)
+		viper.Set("max_expiry_days", nil)
+		viper.Set("max_expiry_days_anon", nil)
+		if tt.limit != "" {
+			if tt.key != "" {
+				viper.Set("max_expiry_days", tt.limit)
+			} else {
+				viper.Set("max_expiry_days_anon", tt.limit)
+			}
+		}
+
+		cmd := NewUploadCmd()
+		_ = cmd.Flags().Set("expires", tt.expires)
+		_, err := resolveExpiry(cmd)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("--expires %s with key %q and limit %q: got error %v", tt.expires, tt.key, tt.limit, err)
+		}
+	}
+}
+
+func TestResolveExpiryNone(t *testing.T) {
+	viper.Set("api_key", "")
+	defer viper.Set("api_key", "test-key")
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("expires", "none")
+	var usageErr *UsageError
+	if _, err := resolveExpiry(cmd); !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "requires an API key") {
+		t.Errorf("Expected an API key to be required, got %v", err)
+	}
+
+	viper.Set("api_key", "test-key")
+	for _, value := range []string{"none", "NEVER"} {
+		_ = cmd.Flags().Set("expires", value)
+		got, err := resolveExpiry(cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != noExpiry {
+			t.Errorf("Expected --expires %s to send %q, got %q", value, noExpiry, got)
+		}
+	}
+}
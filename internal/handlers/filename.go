@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expandFilename replaces the placeholders in a --filename template:
+//
+//	{date}  current date, e.g. 2024-01-31
+//	{time}  current time, e.g. 150405
+//	{unix}  current Unix timestamp in seconds
+//	{ext}   extension of the uploaded file without the dot
+//
+// Literal braces are written as "{{" and "}}".
+func expandFilename(template, ext string, now time.Time) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && strings.HasPrefix(template[i:], "{{"):
+			b.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(template[i:], "}}"):
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return "", usageErrorf("unclosed placeholder in filename: %s", template)
+			}
+			name := template[i+1 : i+end]
+			switch name {
+			case "date":
+				b.WriteString(now.Format("2006-01-02"))
+			case "time":
+				b.WriteString(now.Format("150405"))
+			case "unix":
+				b.WriteString(strconv.FormatInt(now.Unix(), 10))
+			case "ext":
+				b.WriteString(strings.TrimPrefix(ext, "."))
+			default:
+				return "", usageErrorf("unknown filename placeholder: {%s}", name)
+			}
+			i += end
+		case c == '}':
+			return "", usageErrorf("unmatched '}' in filename (use '}}' for a literal brace): %s", template)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	if b.Len() == 0 {
+		return "", usageErrorf("filename is empty")
+	}
+	return b.String(), nil
+}
+
+// compressionExts are the extensions that, after ".tar", form a compound
+// extension kept together by fileExt.
+var compressionExts = []string{".gz", ".bz2", ".xz", ".zst", ".lz", ".lzma", ".z"}
+
+// fileExt returns the extension of name including the dot, like
+// filepath.Ext, except that dotfiles such as ".bashrc" have none and
+// compressed tarballs keep their compound extension, e.g. ".tar.gz".
+func fileExt(name string) string {
+	base := strings.TrimLeft(filepath.Base(name), ".")
+	ext := filepath.Ext(base)
+	if ext == "" {
+		return ""
+	}
+	stem := strings.TrimSuffix(base, ext)
+	if slices.Contains(compressionExts, strings.ToLower(ext)) && strings.EqualFold(filepath.Ext(stem), ".tar") {
+		return filepath.Ext(stem) + ext
+	}
+	return ext
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandFilename(t *testing.T) {
+	now := time.Date(2024, 1, 31, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		template string
+		ext      string
+		want     string
+		wantErr  bool
+	}{
+		{template: "notes.txt", want: "notes.txt"},
+		{template: "screenshot-{date}.png", want: "screenshot-2024-01-31.png"},
+		{template: "{date}_{time}.{ext}", ext: ".png", want: "2024-01-31_150405.png"},
+		{template: "paste-{unix}", want: "paste-1706713445"},
+		{template: "{{literal}}.txt", want: "{literal}.txt"},
+		{template: "{{{date}}}", want: "{2024-01-31}"},
+		{template: "{nope}.txt", wantErr: true},
+		{template: "{date", wantErr: true},
+		{template: "a}b", wantErr: true},
+		{template: "{ext}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandFilename(tt.template, tt.ext, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandFilename(%q): expected error, got %q", tt.template, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandFilename(%q): unexpected error: %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandFilename(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestFileExt(t *testing.T) {
+	tests := map[string]string{
+		"notes.txt":             ".txt",
+		"dir/main.go":           ".go",
+		".bashrc":               "",
+		"/home/me/.bashrc":      "",
+		".config.json":          ".json",
+		"..hidden":              "",
+		"Makefile":              "",
+		"archive.tar.gz":        ".tar.gz",
+		"backup.2024.TAR.XZ":    ".TAR.XZ",
+		"notes.gz":              ".gz",
+		"data.tar":              ".tar",
+		".tar.gz":               ".gz",
+		"release-1.2.3.zip":     ".zip",
+		"/tmp/build.tar.zst":    ".tar.zst",
+		"photo.backup.tar.jpeg": ".jpeg",
+	}
+	for name, want := range tests {
+		if got := fileExt(name); got != want {
+			t.Errorf("fileExt(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+)
+
+// filterItems keeps the items where any of the strings returned by fields
+// contains query, ignoring case.
+func filterItems[T any](items []T, query string, fields func(T) []string) []T {
+	query = strings.ToLower(query)
+	return matchItems(items, func(field string) bool {
+		return strings.Contains(strings.ToLower(field), query)
+	}, fields)
+}
+
+// matchItems keeps the items where match accepts any of the strings
+// returned by fields.
+func matchItems[T any](items []T, match func(string) bool, fields func(T) []string) []T {
+	var matched []T
+	for _, item := range items {
+		for _, field := range fields(item) {
+			if match(field) {
+				matched = append(matched, item)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// dateRange limits listings to items created at or after since and before
+// until. A zero bound leaves that side open.
+type dateRange struct {
+	since time.Time
+	until time.Time
+}
+
+// parseDateRange parses the --since and --until flags. A bare date given to
+// --until includes that whole day.
+func parseDateRange(since, until string) (dateRange, error) {
+	var r dateRange
+	if since != "" {
+		t, ok := parseTime(since)
+		if !ok {
+			return r, usageErrorf("invalid --since date: %s (use YYYY-MM-DD or RFC3339)", since)
+		}
+		r.since = t
+	}
+	if until != "" {
+		t, ok := parseTime(until)
+		if !ok {
+			return r, usageErrorf("invalid --until date: %s (use YYYY-MM-DD or RFC3339)", until)
+		}
+		if len(until) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		r.until = t
+	}
+	if !r.since.IsZero() && !r.until.IsZero() && !r.since.Before(r.until) {
+		return r, usageErrorf("--since must be before --until")
+	}
+	return r, nil
+}
+
+func (r dateRange) isZero() bool {
+	return r.since.IsZero() && r.until.IsZero()
+}
+
+// contains reports whether an RFC3339 creation time falls within r. Items
+// with a missing or malformed time are left out once a bound is set.
+func (r dateRange) contains(createdAt string) bool {
+	if r.isZero() {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return false
+	}
+	if !r.since.IsZero() && t.Before(r.since) {
+		return false
+	}
+	if !r.until.IsZero() && !t.Before(r.until) {
+		return false
+	}
+	return true
+}
+
+// filterByDate keeps the items whose creation time is within r.
+func filterByDate[T any](items []T, r dateRange, createdAt func(T) string) []T {
+	if r.isZero() {
+		return items
+	}
+	var matched []T
+	for _, item := range items {
+		if r.contains(createdAt(item)) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/url"
+	"path"
+
+	"github.com/dustin/go-humanize"
+	"github.com/watzon/0x45-cli/internal/client"
+)
+
+// defaultFetchMaxSize caps --from-url downloads when neither --max-size nor
+// max_upload_size is set.
+const defaultFetchMaxSize = 100 * humanize.MByte
+
+// remoteSource is a download being streamed into an upload.
+type remoteSource struct {
+	body     *limitReader
+	closer   io.Closer
+	size     int64
+	mimeType string
+}
+
+// fetchRemote starts downloading rawURL, refusing it up front if the server
+// says it is larger than maxSize. The body is limited to maxSize as it is
+// read, for servers that don't send a length.
+func fetchRemote(rawURL string, maxSize int64) (*remoteSource, error) {
+	resp, err := client.Fetch(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSize(maxSize, resp.ContentLength, rawURL); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if _, _, err := mime.ParseMediaType(mimeType); err != nil {
+		mimeType = ""
+	}
+	return &remoteSource{
+		body:     limitSize(resp.Body, maxSize),
+		closer:   resp.Body,
+		size:     resp.ContentLength,
+		mimeType: mimeType,
+	}, nil
+}
+
+// remoteFilename derives an upload's name from the last element of the URL
+// path.
+func remoteFilename(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "download"
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return "download"
+	}
+	return name
+}
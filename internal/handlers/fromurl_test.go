@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerFromURL(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Expected the API key not to be sent to the remote server")
+		}
+		switch r.URL.Path {
+		case "/images/cat.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG fake image"))
+		case "/big":
+			// Flushing first leaves the length unknown, so the limit has to
+			// be enforced while streaming.
+			w.(http.Flusher).Flush()
+			_, _ = w.Write(bytes.Repeat([]byte("x"), 2000))
+		}
+	}))
+	defer remote.Close()
+
+	var filename, contentType, body string
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		filename, contentType, body = r.Header.Get("X-Filename"), r.Header.Get("Content-Type"), string(data)
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("from-url", remote.URL+"/images/cat.png?size=large")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if filename != "cat.png" || contentType != "image/png" || body != "\x89PNG fake image" {
+		t.Errorf("Unexpected upload: filename %q, content type %q, body %q", filename, contentType, body)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"known length", "/images/cat.png"},
+		{"streamed", "/big"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads = 0
+			cmd := NewUploadCmd()
+			_ = cmd.Flags().Set("from-url", remote.URL+tt.path)
+			_ = cmd.Flags().Set("max-size", "10B")
+			var usageErr *UsageError
+			err := Upload(cmd, nil)
+			if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "larger than the maximum of 10 B") {
+				t.Errorf("Expected a size limit error, got %v", err)
+			}
+			if tt.path == "/images/cat.png" && uploads != 0 {
+				t.Errorf("Expected nothing to be uploaded, got %d uploads", uploads)
+			}
+		})
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("from-url", "ftp://example.com/file")
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for a non-HTTP URL, got %v", err)
+	}
+}
+
+func TestRemoteFilename(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/images/cat.png":   "cat.png",
+		"https://example.com/dir/file.txt?x=1": "file.txt",
+		"https://example.com/":                 "download",
+		"https://example.com":                  "download",
+	}
+	for rawURL, want := range tests {
+		if got := remoteFilename(rawURL); got != want {
+			t.Errorf("remoteFilename(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func NewGetCmd() *cobra.Command {
+	var output string
+	var force bool
+	var password string
+	var decrypt string
+
+	cmd := &cobra.Command{
+		Use:     "get [id or url]",
+		Aliases: []string{"download"},
+		Short:   "Download the content of a paste",
+		Long: `Download the content of a paste by its ID or URL.
+
+Encrypted pastes are decrypted when given the URL printed by
+'0x45 upload --encrypt', whose #key=... fragment holds the key, or an ID
+together with --decrypt.
+
+With --base64 the content is written as a single line of base64, for
+shells and scripts that can't handle binary output.`,
+		Args: cobra.ExactArgs(1),
+		RunE: Get,
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file or directory instead of stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the output file if it exists")
+	cmd.Flags().StringVar(&password, "password", "", "Password of a protected paste, or - to prompt for it")
+	cmd.Flags().Bool("base64", false, "Write the content as base64")
+	cmd.Flags().StringVar(&decrypt, "decrypt", "", "Key to decrypt an encrypted paste with (the part after #key= in its URL)")
+
+	return cmd
+}
+
+func Get(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return err
+	}
+
+	toBase64, err := cmd.Flags().GetBool("base64")
+	if err != nil {
+		return err
+	}
+	copyBody := io.Copy
+	if toBase64 {
+		copyBody = copyBase64
+	}
+
+	password, err := passwordFlag(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	id, fragmentKey := splitPasteRef(args[0])
+	decrypt, err := cmd.Flags().GetString("decrypt")
+	if err != nil {
+		return err
+	}
+	if decrypt == "" {
+		decrypt = fragmentKey
+	}
+	var key []byte
+	if decrypt != "" {
+		if key, err = parseKey(decrypt); err != nil {
+			return err
+		}
+	}
+
+	resp, err := client.DownloadWithPassword(id, password)
+	// A protected paste asks for its password when none was given.
+	if password == "" && passwordRequired(err) && canPrompt(cmd) {
+		if password, err = promptPassword(cmd, false); err != nil {
+			return err
+		}
+		resp, err = client.DownloadWithPassword(id, password)
+	}
+	if err != nil {
+		if password != "" && passwordRequired(err) {
+			return fmt.Errorf("error downloading paste: wrong password: %w", err)
+		}
+		if errors.Is(err, api.ErrNotFound) {
+			return notFoundErrorf("paste not found: %s", id)
+		}
+		return wrapAPIError("error downloading paste", err)
+	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if key != nil {
+		sealed, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("error downloading paste: %w", err)
+		}
+		plaintext, err := openContent(sealed, key)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(plaintext)
+	}
+
+	if output == "" {
+		n, err := copyBody(cmd.OutOrStdout(), body)
+		if err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+		if !quietOutput() {
+			fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatSuccess(fmt.Sprintf("Wrote %d bytes", n)))
+		}
+		return nil
+	}
+
+	if info, err := os.Stat(output); err == nil && info.IsDir() {
+		filename := resp.Filename
+		if filename == "" || filename == "." || filename == string(filepath.Separator) {
+			filename = id
+		}
+		output = filepath.Join(output, filename)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(output, flags, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("file already exists: %s (use --force to overwrite)", output)
+		}
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer file.Close()
+
+	n, err := copyBody(file, body)
+	if err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+
+	if !quietOutput() {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("Wrote %d bytes to %s", n, output)))
+	}
+	return nil
+}
+
+// passwordRequired reports whether err is the server refusing a protected
+// paste for lack of the right password.
+func passwordRequired(err error) bool {
+	var apiErr *api.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
@@ -1,67 +1,839 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
 	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
 )
 
+var errEmptyUpload = errors.New("refusing to upload empty content (use --allow-empty to override)")
+
 func NewUploadCmd() *cobra.Command {
 	var private bool
 	var expires string
+	var expiresAt string
+	var copyURL bool
+	var showQR bool
+	var noProgress bool
+	var mimeType string
+	var allowEmpty bool
+	var dryRun bool
+	var filename string
+	var archive string
+	var stdinName string
+	var content string
+	var lang string
+	var dedupe bool
+	var force bool
+	var concurrency int
+	var title string
+	var password string
+	var encrypt bool
+	var limitRate string
+	var ifNewer string
+	var fromURL string
+	var maxSize string
+	var appendFiles []string
+	var resumable bool
+	var chunkSize string
+	var replace string
 
 	cmd := &cobra.Command{
-		Use:   "upload [file]",
-		Short: "Upload a file to 0x45.st",
-		Args:  cobra.ExactArgs(1),
-		RunE:  Upload,
+		Use:   "upload [file...]",
+		Short: "Upload files to 0x45.st",
+		Long: `Upload a file to 0x45.st.
+
+With no file, or "-", the content is read from stdin and its type is detected
+from the first bytes. With several files, each becomes its own paste and they
+are uploaded in parallel. To combine several files into one paste instead,
+give each with --append.
+
+For large files over flaky connections, --resumable sends the file in chunks
+and remembers how far it got, so running the same command again after an
+interruption carries on from the last chunk the server stored. Servers
+without chunked uploads get a regular upload instead.`,
+		Example: "  0x45 upload notes.md\n  0x45 upload --append header.txt --append body.txt\n  0x45 upload --resumable --chunk-size 4MB backup.tar.gz",
+		Args:    cobra.ArbitraryArgs,
+		RunE:    Upload,
+		// Public uploads work without an API key.
+		Annotations: map[string]string{apiKeyAnnotation: apiKeyOptional},
+	}
+
+	cmd.Flags().BoolVar(&private, "private", false, "Make the upload private")
+	cmd.Flags().StringVar(&expires, "expires", "", "Set expiration time (e.g. 24h, 7d, 2w, or none with an API key)")
+	cmd.Flags().StringVar(&expiresAt, "expires-at", "", "Set an absolute expiration time (YYYY-MM-DD or RFC3339)")
+	cmd.MarkFlagsMutuallyExclusive("expires", "expires-at")
+	cmd.Flags().BoolVarP(&copyURL, "copy", "c", false, "Copy the resulting URL to the clipboard")
+	cmd.Flags().BoolVar(&showQR, "qr", false, "Print a QR code of the resulting URL")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable the upload progress bar")
+	cmd.Flags().StringVarP(&mimeType, "mime", "m", "", "Force the content type of the upload (e.g. text/markdown)")
+	cmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, "Allow uploading empty content")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and show the request without uploading")
+	cmd.Flags().StringVar(&filename, "filename", "", "Name for the upload; supports {date}, {time}, {unix} and {ext}")
+	cmd.Flags().StringVar(&archive, "archive", "", "Upload a directory as an archive (tar.gz or zip)")
+	cmd.Flags().StringVar(&stdinName, "stdin-name", "", "Filename to use for content read from stdin")
+	cmd.Flags().StringVarP(&content, "content", "C", "", "Upload this text instead of a file or stdin")
+	cmd.Flags().Bool("base64", false, "Decode base64 from stdin or --content before uploading")
+	cmd.Flags().StringVar(&lang, "lang", "", "Language for syntax highlighting (e.g. go, python) or a file extension")
+	cmd.Flags().BoolVar(&dedupe, "dedupe", false, "Reuse the URL of an earlier upload of the same content")
+	cmd.Flags().BoolVar(&force, "force", false, "With --dedupe, upload again even if the content was uploaded before; with --save-meta, overwrite an existing sidecar")
+	cmd.Flags().String("field", "", "Print only this field of the response (e.g. url)")
+	cmd.Flags().String("format", "", "Print the response with a Go template (e.g. '{{.url}}')")
+	cmd.Flags().StringVar(&title, "title", "", "Human-friendly title shown with the paste")
+	cmd.Flags().StringVar(&password, "password", "", "Require this password to view the paste, or - to prompt for it (needs server support)")
+	cmd.Flags().BoolVar(&encrypt, "encrypt", false, "Encrypt the content before uploading; the key is only kept in the URL fragment")
+	cmd.Flags().StringVar(&limitRate, "limit-rate", "", "Upload no faster than this many bytes per second (e.g. 500k, 2m)")
+	cmd.Flags().StringVar(&ifNewer, "if-newer", "", "Only upload the file if it changed after this paste (ID or URL) was uploaded")
+	cmd.Flags().StringVar(&fromURL, "from-url", "", "Download this URL and upload its content")
+	cmd.Flags().StringVar(&maxSize, "max-size", "", "Refuse uploads larger than this (e.g. 10MB; default max_upload_size, or 100MB with --from-url)")
+	cmd.Flags().StringArrayVar(&appendFiles, "append", nil, "Add this file to a single combined paste (repeatable, in order)")
+	cmd.Flags().BoolVar(&resumable, "resumable", false, "Upload the file in chunks that can be resumed after an interruption (needs server support)")
+	cmd.Flags().StringVar(&chunkSize, "chunk-size", "", "Size of each chunk with --resumable (default 8MiB)")
+	cmd.Flags().StringVar(&replace, "replace", "", "Upload as the new content of this paste (ID or URL), keeping its URL if the server supports it")
+	cmd.Flags().Bool("save-meta", false, "Save the upload's URLs and details to <file>"+metaSuffix+" next to the file")
+	cmd.Flags().String("meta-file", "", "Save the upload's URLs and details to this file instead (implies --save-meta)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of files to upload at once when uploading several")
+
+	return cmd
+}
+
+func Upload(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return uploadFiles(cmd, args)
+	}
+
+	content, err := cmd.Flags().GetString("content")
+	if err != nil {
+		return err
+	}
+	useContent := cmd.Flags().Changed("content")
+	if useContent && len(args) > 0 {
+		return usageErrorf("--content cannot be combined with a file argument or stdin")
+	}
+
+	appendFiles, err := cmd.Flags().GetStringArray("append")
+	if err != nil {
+		return err
+	}
+	useAppend := len(appendFiles) > 0
+	if useAppend && (useContent || len(args) > 0) {
+		return usageErrorf("--append cannot be combined with a file argument, stdin or --content")
+	}
+
+	fromURL, err := cmd.Flags().GetString("from-url")
+	if err != nil {
+		return err
+	}
+	useURL := fromURL != ""
+	if useURL {
+		if useContent || useAppend || len(args) > 0 {
+			return usageErrorf("--from-url cannot be combined with a file argument, stdin, --content or --append")
+		}
+		if err := validateShortenURL(fromURL, false); err != nil {
+			return err
+		}
+	}
+	var fallbackMaxSize int64
+	if useURL {
+		fallbackMaxSize = defaultFetchMaxSize
+	}
+	maxSize, err := maxSizeFlag(cmd, fallbackMaxSize)
+	if err != nil {
+		return err
+	}
+
+	// With no argument, or "-", the content is read from stdin.
+	readStdin := !useContent && !useURL && !useAppend && (len(args) == 0 || args[0] == "-")
+
+	stdinName, err := cmd.Flags().GetString("stdin-name")
+	if err != nil {
+		return err
+	}
+	if stdinName != "" && !readStdin {
+		return usageErrorf("--stdin-name can only be used when reading from stdin")
+	}
+
+	// With --base64, --content is decoded up front and stdin as it is read.
+	fromBase64, err := cmd.Flags().GetBool("base64")
+	if err != nil {
+		return err
+	}
+	if fromBase64 && !useContent && !readStdin {
+		return usageErrorf("--base64 can only be used with stdin or --content")
+	}
+	if fromBase64 && useContent {
+		decoded, err := decodeBase64(content, "--content")
+		if err != nil {
+			return err
+		}
+		content = string(decoded)
+	}
+
+	var filePath string
+	var isDir bool
+	var appendTotal int64
+	switch {
+	case useContent, useURL:
+	case useAppend:
+		if appendTotal, err = appendSize(appendFiles); err != nil {
+			return err
+		}
+		if err := checkSize(maxSize, appendTotal, "the combined files"); err != nil {
+			return err
+		}
+	case readStdin:
+		if len(args) == 0 && stdinIsTerminal(cmd) {
+			return usageErrorf("no file given: pass a file path, --content, or pipe content on stdin")
+		}
+	default:
+		filePath = args[0]
+		pathInfo, err := os.Stat(filePath)
+		if os.IsNotExist(err) {
+			return usageErrorf("file does not exist: %s", filePath)
+		}
+		if err != nil {
+			return fmt.Errorf("error getting file info: %w", err)
+		}
+		isDir = pathInfo.IsDir()
+		if !isDir {
+			if err := checkSize(maxSize, pathInfo.Size(), filePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	archive, err := cmd.Flags().GetString("archive")
+	if err != nil {
+		return err
+	}
+	if isDir {
+		if archive == "" {
+			return usageErrorf("%s is a directory (use --archive tar.gz or --archive zip to upload it as an archive)", filePath)
+		}
+		if err := validateArchiveFormat(archive); err != nil {
+			return err
+		}
+	} else if archive != "" {
+		return usageErrorf("--archive can only be used when uploading a directory")
+	}
+
+	private, err := cmd.Flags().GetBool("private")
+	if err != nil {
+		return err
+	}
+	if err := checkPrivateUpload(private); err != nil {
+		return err
+	}
+
+	expires, err := resolveExpiry(cmd)
+	if err != nil {
+		return err
+	}
+
+	copyURL, err := shouldCopy(cmd)
+	if err != nil {
+		return err
+	}
+
+	showQR, err := cmd.Flags().GetBool("qr")
+	if err != nil {
+		return err
+	}
+
+	noProgress, err := cmd.Flags().GetBool("no-progress")
+	if err != nil {
+		return err
+	}
+
+	mimeType, err := mimeTypeFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	allowEmpty, err := cmd.Flags().GetBool("allow-empty")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	field, err := cmd.Flags().GetString("field")
+	if err != nil {
+		return err
+	}
+	if err := checkField(field, api.UploadResponse{}); err != nil {
+		return err
+	}
+	if _, err := formatFlag(cmd); err != nil {
+		return err
+	}
+
+	langExt, err := langFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	dedupe, err := cmd.Flags().GetBool("dedupe")
+	if err != nil {
+		return err
+	}
+	if dedupe && (readStdin || isDir || useURL || useAppend) {
+		return usageErrorf("--dedupe can only be used when uploading a file or --content")
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return err
+	}
+
+	title, err := cmd.Flags().GetString("title")
+	if err != nil {
+		return err
+	}
+	title = strings.TrimSpace(title)
+	if strings.ContainsAny(title, "\r\n") {
+		return usageErrorf("--title must be a single line")
+	}
+
+	if readStdin && cmd.Flags().Lookup("password").Value.String() == askPassword {
+		return usageErrorf("--password - can't prompt while the upload is read from stdin; pass the password instead")
+	}
+	password, err := passwordFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+	if password != "" && dedupe {
+		return usageErrorf("--dedupe can't be combined with --password")
+	}
+
+	limiter, err := limitRateFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	ifNewer, err := cmd.Flags().GetString("if-newer")
+	if err != nil {
+		return err
+	}
+	if ifNewer != "" && (filePath == "" || isDir) {
+		return usageErrorf("--if-newer can only be used when uploading a file")
+	}
+
+	encrypt, err := cmd.Flags().GetBool("encrypt")
+	if err != nil {
+		return err
+	}
+	if encrypt {
+		for _, name := range []string{"archive", "dedupe", "mime", "lang", "resumable"} {
+			if cmd.Flags().Changed(name) {
+				return usageErrorf("--%s can't be combined with --encrypt", name)
+			}
+		}
+	}
+
+	resumable, err := cmd.Flags().GetBool("resumable")
+	if err != nil {
+		return err
+	}
+	if resumable && (filePath == "" || isDir) {
+		return usageErrorf("--resumable can only be used when uploading a file")
+	}
+	if cmd.Flags().Changed("chunk-size") && !resumable {
+		return usageErrorf("--chunk-size can only be used with --resumable")
+	}
+	chunkSize, err := chunkSizeFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	replace, err := cmd.Flags().GetString("replace")
+	if err != nil {
+		return err
+	}
+	if replace != "" {
+		replace, _ = splitPasteRef(replace)
+		if isDir {
+			return usageErrorf("--replace can't be used when uploading a directory")
+		}
+		for _, name := range []string{"dedupe", "resumable"} {
+			if cmd.Flags().Changed(name) {
+				return usageErrorf("--%s can't be combined with --replace", name)
+			}
+		}
+	}
+
+	meta, err := metaSidecarFlag(cmd, filePath)
+	if err != nil {
+		return err
+	}
+
+	// Directories are archived on the fly and stdin is streamed, so neither
+	// has a size known before the upload finishes.
+	var stream io.Reader
+	streamSize := int64(-1)
+	var remote *remoteSource
+	// guard enforces --max-size on content whose size isn't known up front.
+	var guard *limitReader
+	guarded := ""
+	// decoder is set with --base64, whose errors stop a streamed upload.
+	var decoder *base64Reader
+	localName := filepath.Base(filePath)
+	ext := fileExt(localName)
+	switch {
+	case useContent:
+		if content == "" && !allowEmpty {
+			return errEmptyUpload
+		}
+		if err := checkSize(maxSize, int64(len(content)), "--content"); err != nil {
+			return err
+		}
+		stream = strings.NewReader(content)
+		streamSize = int64(len(content))
+		localName = "paste.txt"
+		if langExt != "" {
+			localName = "paste." + langExt
+		}
+		if fromBase64 {
+			// Decoded content is as likely to be binary as piped content.
+			detectedExt, detectedMime := detectContentType([]byte(content[:min(len(content), sniffLen)]))
+			localName = stdinFilename(localName, detectedExt, langExt)
+			if mimeType == "" {
+				mimeType = detectedMime
+			}
+		}
+		ext = fileExt(localName)
+	case readStdin:
+		in := cmd.InOrStdin()
+		if fromBase64 {
+			decoder = newBase64Reader(in, "stdin")
+			in = decoder
+		}
+		var head []byte
+		stream, head, err = peekContent(in)
+		if decoder != nil && decoder.err != nil {
+			return decoder.err
+		}
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		if len(head) == 0 && !allowEmpty {
+			return errEmptyUpload
+		}
+		if guard = limitSize(stream, maxSize); guard != nil {
+			stream, guarded = guard, "stdin"
+		}
+
+		detectedExt, detectedMime := detectContentType(head)
+		localName = stdinFilename(viper.GetString("default_stdin_filename"), detectedExt, langExt)
+		if stdinName != "" {
+			localName = stdinName
+		}
+		ext = fileExt(localName)
+		if mimeType == "" {
+			mimeType = detectedMime
+		}
+	case useURL:
+		localName = remoteFilename(fromURL)
+		ext = fileExt(localName)
+		if !dryRun {
+			remote, err = fetchRemote(fromURL, maxSize)
+			if err != nil {
+				return err
+			}
+			defer remote.closer.Close()
+			if remote.size == 0 && !allowEmpty {
+				return errEmptyUpload
+			}
+			stream, streamSize = remote.body, remote.size
+			guard, guarded = remote.body, fromURL
+			if mimeType == "" {
+				mimeType = remote.mimeType
+			}
+		}
+	case useAppend:
+		if appendTotal == 0 && !allowEmpty {
+			return errEmptyUpload
+		}
+		localName = filepath.Base(appendFiles[0])
+		ext = fileExt(localName)
+		streamSize = appendTotal
+		if !dryRun {
+			var closeAll func()
+			if stream, closeAll, err = openAppend(appendFiles); err != nil {
+				return err
+			}
+			defer closeAll()
+			if !jsonOutput() && !quietOutput() {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Combining %d files (%s)\n", len(appendFiles), humanize.Bytes(uint64(appendTotal)))
+			}
+		}
+	case isDir:
+		localName = filepath.Base(filepath.Clean(filePath)) + "." + archive
+		ext = archive
+		if mimeType == "" {
+			mimeType = archiveMimeTypes[archive]
+		}
+	}
+
+	filename, err := cmd.Flags().GetString("filename")
+	if err != nil {
+		return err
+	}
+	if filename == "" {
+		filename = localName
+	} else {
+		filename, err = expandFilename(filename, ext, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := api.UploadOptions{
+		Filename:  filename,
+		Private:   private,
+		Expires:   expires,
+		MimeType:  mimeType,
+		Extension: langExt,
+		Title:     title,
+		Password:  password,
+	}
+	// The server only ever sees opaque bytes.
+	if encrypt {
+		opts.MimeType = encryptedMimeType
+		opts.Extension = ""
+	}
+
+	// done prints the result of the upload and, with --save-meta, records
+	// it next to the file.
+	done := func(resp *api.UploadResponse, archiveSize string) error {
+		if err := printUploadResult(cmd, resp, copyURL, showQR, archiveSize); err != nil {
+			return err
+		}
+		return meta.save(resp, opts)
+	}
+
+	// With --dedupe, content uploaded before is answered with the earlier URL
+	// and new uploads are remembered by their hash.
+	var hash string
+	if dedupe {
+		if useContent {
+			hash, err = hashReader(strings.NewReader(content))
+		} else {
+			hash, err = hashFile(filePath)
+		}
+		if err != nil {
+			return err
+		}
+		if !force && !dryRun {
+			cached, err := findUpload(hash, opts)
+			if err != nil {
+				return err
+			}
+			if cached != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatWarning(fmt.Sprintf("Already uploaded as %s, reusing its URL (use --force to upload again)", cached.Filename)))
+				resp := &api.UploadResponse{Success: true, URL: cached.URL, DeleteURL: cached.DeleteURL}
+				return done(resp, "")
+			}
+		}
+	}
+
+	if useContent || readStdin || isDir || useURL || useAppend {
+		if dryRun {
+			method, endpoint := uploadRequest(replace)
+			req := dryRunRequest{
+				Method:   method,
+				Endpoint: endpoint,
+				Filename: filename,
+				URL:      fromURL,
+				MimeType: mimeType,
+				Private:  private,
+				Expires:  expires,
+				Title:    title,
+				Password: password != "",
+				Encrypt:  encrypt,
+			}
+			if streamSize >= 0 {
+				req.Size = &streamSize
+			}
+			return printDryRun(cmd, req)
+		}
+		if isDir {
+			resp, archiveSize, err := uploadArchive(filePath, archive, opts, limiter, maxSize)
+			if err != nil {
+				return err
+			}
+			return done(resp, humanize.Bytes(uint64(archiveSize)))
+		}
+
+		var key []byte
+		if encrypt {
+			if stream, streamSize, key, err = sealUpload(stream); err != nil {
+				if guard.exceeded() {
+					return sizeLimitError(maxSize, guarded)
+				}
+				if decoder != nil && decoder.err != nil {
+					return decoder.err
+				}
+				return err
+			}
+		}
+
+		logging.Infof("Uploading %s", filename)
+		resp, err := sendUpload(replace, limiter.reader(stream), streamSize, opts)
+		if guard.exceeded() {
+			return sizeLimitError(maxSize, guarded)
+		}
+		if decoder != nil && decoder.err != nil {
+			return decoder.err
+		}
+		if err != nil {
+			return wrapAPIError("error uploading content", err)
+		}
+		addKeyFragment(resp, key)
+		rememberUpload(hash, filename, opts, resp)
+		return done(resp, "")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error getting file info: %w", err)
+	}
+
+	if fileInfo.Size() == 0 && !allowEmpty {
+		return errEmptyUpload
+	}
+
+	// With --if-newer, an unchanged file is answered with the existing
+	// paste instead of being uploaded again.
+	if ifNewer != "" {
+		id, _ := splitPasteRef(ifNewer)
+		existing, err := findPaste(id)
+		if err != nil {
+			return err
+		}
+		newer, err := newerThanPaste(fileInfo.ModTime(), existing)
+		if err != nil {
+			return err
+		}
+		if !newer {
+			fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatWarning(fmt.Sprintf("%s hasn't changed since paste %s was uploaded, skipping the upload", filePath, existing.Id)))
+			resp := &api.UploadResponse{Success: true, URL: existing.URL, DeleteURL: existing.DeleteURL, Title: existing.Title}
+			return done(resp, "")
+		}
+	}
+
+	if dryRun {
+		size := fileInfo.Size()
+		method, endpoint := uploadRequest(replace)
+		if resumable {
+			endpoint = client.BaseURL() + "/uploads"
+		}
+		return printDryRun(cmd, dryRunRequest{
+			Method:   method,
+			Endpoint: endpoint,
+			Filename: filename,
+			Size:     &size,
+			MimeType: mimeType,
+			Private:  private,
+			Expires:  expires,
+			Title:    title,
+			Password: password != "",
+			Encrypt:  encrypt,
+		})
+	}
+
+	showProgress := !noProgress && !jsonOutput() && isTerminal()
+	if resumable {
+		// Progress is kept by content hash, which --dedupe may already have.
+		stateKey := hash
+		if stateKey == "" {
+			if stateKey, err = hashFile(filePath); err != nil {
+				return err
+			}
+		}
+		logging.Infof("Uploading %s as %s in chunks of %s", filePath, filename, humanize.Bytes(uint64(chunkSize)))
+		resp, err := uploadResumable(cmd, file, fileInfo.Size(), stateKey, chunkSize, opts, limiter, showProgress)
+		if err == nil {
+			rememberUpload(hash, filename, opts, resp)
+			return done(resp, "")
+		}
+		if !errors.Is(err, api.ErrChunkedUnsupported) {
+			return err
+		}
+		logging.Warnf("%v, uploading in one request instead", err)
+	}
+
+	var body io.Reader = file
+	size := fileInfo.Size()
+	var key []byte
+	if encrypt {
+		if body, size, key, err = sealUpload(file); err != nil {
+			return err
+		}
+	}
+
+	body = limiter.reader(body)
+
+	var progress *progressReader
+	if showProgress && size > 0 {
+		progress = newProgressReader(body, size, cmd.ErrOrStderr())
+		body = progress
+	}
+
+	logging.Infof("Uploading %s as %s (%s)", filePath, filename, humanize.Bytes(uint64(size)))
+	resp, err := sendUpload(replace, body, size, opts)
+	if progress != nil {
+		progress.Finish()
+	}
+	if err != nil {
+		return wrapAPIError("error uploading file", err)
+	}
+	addKeyFragment(resp, key)
+
+	rememberUpload(hash, filename, opts, resp)
+	return done(resp, "")
+}
+
+// checkPrivateUpload requires an API key for private uploads.
+func checkPrivateUpload(private bool) error {
+	if !private {
+		return nil
+	}
+	apiKey, err := client.APIKey()
+	if err != nil {
+		return err
+	}
+	if apiKey == "" {
+		return usageErrorf("private uploads require an API key. Run '0x45 config set api_key YOUR_API_KEY' to set it")
+	}
+	return nil
+}
+
+// mimeTypeFlag returns the validated --mime value.
+func mimeTypeFlag(cmd *cobra.Command) (string, error) {
+	mimeType, err := cmd.Flags().GetString("mime")
+	if err != nil {
+		return "", err
+	}
+	if mimeType != "" {
+		if _, _, err := mime.ParseMediaType(mimeType); err != nil {
+			return "", usageErrorf("invalid MIME type: %s", mimeType)
+		}
+	}
+	return mimeType, nil
+}
+
+// langFlag returns the file extension for the --lang value, if any.
+func langFlag(cmd *cobra.Command) (string, error) {
+	lang, err := cmd.Flags().GetString("lang")
+	if err != nil || lang == "" {
+		return "", err
 	}
+	langExt := langExtension(lang)
+	if langExt == "" || strings.ContainsAny(langExt, "./\\ ") {
+		return "", usageErrorf("invalid language: %s", lang)
+	}
+	return langExt, nil
+}
 
-	cmd.Flags().BoolVar(&private, "private", false, "Make the upload private")
-	cmd.Flags().StringVar(&expires, "expires", "", "Set expiration time (e.g. 24h)")
+// uploadArchive streams an archive of dir as the upload body, returning the
+// response and the size of the archive.
+func uploadArchive(dir, format string, opts api.UploadOptions, limiter *rateLimiter, maxSize int64) (*api.UploadResponse, int64, error) {
+	logging.Infof("Uploading %s as a %s archive", dir, format)
+	archive := streamArchive(dir, format)
+	defer archive.Close()
 
-	return cmd
+	var body io.Reader = archive
+	guard := limitSize(archive, maxSize)
+	if guard != nil {
+		body = guard
+	}
+	counter := &countingReader{r: limiter.reader(body)}
+	resp, err := client.UploadReader(counter, -1, opts)
+	if guard.exceeded() {
+		return nil, 0, sizeLimitError(maxSize, "the archive of "+dir)
+	}
+	if err != nil {
+		return nil, 0, wrapAPIError("error uploading directory", err)
+	}
+	return resp, counter.n, nil
 }
 
-func Upload(cmd *cobra.Command, args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("expected 1 argument, got %d", len(args))
+// printUploadResult prints the URLs from a successful upload. archiveSize is
+// shown when a directory was uploaded as an archive.
+func printUploadResult(cmd *cobra.Command, resp *api.UploadResponse, copyURL, showQR bool, archiveSize string) error {
+	if !resp.Success {
+		return fmt.Errorf("error uploading file: %s", resp.Error)
 	}
 
-	filePath := args[0]
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %s", filePath)
+	// Servers that don't echo the title back still stored the one sent.
+	if title, _ := cmd.Flags().GetString("title"); resp.Title == "" {
+		resp.Title = strings.TrimSpace(title)
 	}
 
-	private, err := cmd.Flags().GetBool("private")
-	if err != nil {
-		return err
+	if copyURL {
+		copyToClipboard(cmd, resp.URL)
 	}
 
-	expires, err := cmd.Flags().GetString("expires")
-	if err != nil {
-		return err
+	if field, _ := cmd.Flags().GetString("field"); field != "" {
+		return printField(cmd, resp, field)
 	}
 
-	resp, err := client.UploadFile(filePath, private, expires)
-	if err != nil {
-		return fmt.Errorf("error uploading file: %w", err)
+	if tmpl, _ := formatFlag(cmd); tmpl != nil {
+		return printTemplate(cmd, tmpl, resp)
 	}
 
-	if !resp.Success {
-		return fmt.Errorf("error uploading file: %s", resp.Error)
+	if jsonOutput() {
+		return printJSON(cmd, resp)
 	}
 
 	fmt.Fprintln(cmd.OutOrStdout(), resp.URL)
+	if quietOutput() {
+		if showQR {
+			return printQR(cmd, resp.URL)
+		}
+		return nil
+	}
+	if resp.Title != "" {
+		fmt.Fprintln(cmd.OutOrStdout(), "Title:", resp.Title)
+	}
 	if resp.DeleteURL != "" {
 		fmt.Fprintln(cmd.OutOrStdout(), "Delete URL:", resp.DeleteURL)
 	}
+	if resp.MimeType != "" {
+		fmt.Fprintln(cmd.OutOrStdout(), "Type:", resp.MimeType)
+	}
+	if archiveSize != "" {
+		fmt.Fprintln(cmd.OutOrStdout(), "Archive size:", archiveSize)
+	}
+
+	if showQR {
+		return printQR(cmd, resp.URL)
+	}
 
 	return nil
 }
@@ -69,6 +841,11 @@ func Upload(cmd *cobra.Command, args []string) error {
 func NewShortenCmd() *cobra.Command {
 	var private bool
 	var expires string
+	var expiresAt string
+	var copyURL bool
+	var showQR bool
+	var dryRun bool
+	var allowAnyScheme bool
 
 	cmd := &cobra.Command{
 		Use:   "shorten [url]",
@@ -78,14 +855,31 @@ func NewShortenCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&private, "private", false, "Make the URL private")
-	cmd.Flags().StringVar(&expires, "expires", "", "Set expiration time (e.g. 24h)")
+	cmd.Flags().StringVar(&expires, "expires", "", "Set expiration time (e.g. 24h, 7d, 2w, or none with an API key)")
+	cmd.Flags().StringVar(&expiresAt, "expires-at", "", "Set an absolute expiration time (YYYY-MM-DD or RFC3339)")
+	cmd.MarkFlagsMutuallyExclusive("expires", "expires-at")
+	cmd.Flags().BoolVarP(&copyURL, "copy", "c", false, "Copy the resulting URL to the clipboard")
+	cmd.Flags().BoolVar(&showQR, "qr", false, "Print a QR code of the resulting URL")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and show the request without shortening")
+	cmd.Flags().BoolVar(&allowAnyScheme, "allow-any-scheme", false, "Allow URLs with schemes other than http and https")
+	cmd.Flags().String("field", "", "Print only this field of the response (e.g. url)")
+	cmd.Flags().String("format", "", "Print the response with a Go template (e.g. '{{.url}}')")
 
 	return cmd
 }
 
 func Shorten(cmd *cobra.Command, args []string) error {
 	if len(args) != 1 {
-		return fmt.Errorf("expected 1 argument, got %d", len(args))
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+
+	allowAnyScheme, err := cmd.Flags().GetBool("allow-any-scheme")
+	if err != nil {
+		return err
+	}
+
+	if err := validateShortenURL(args[0], allowAnyScheme); err != nil {
+		return err
 	}
 
 	private, err := cmd.Flags().GetBool("private")
@@ -93,41 +887,145 @@ func Shorten(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	expires, err := cmd.Flags().GetString("expires")
+	expires, err := resolveExpiry(cmd)
+	if err != nil {
+		return err
+	}
+
+	copyURL, err := shouldCopy(cmd)
+	if err != nil {
+		return err
+	}
+
+	showQR, err := cmd.Flags().GetBool("qr")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	field, err := cmd.Flags().GetString("field")
+	if err != nil {
+		return err
+	}
+	if err := checkField(field, api.ShortenResponse{}); err != nil {
+		return err
+	}
+
+	tmpl, err := formatFlag(cmd)
 	if err != nil {
 		return err
 	}
 
+	if dryRun {
+		return printDryRun(cmd, dryRunRequest{
+			Method:   "POST",
+			Endpoint: client.BaseURL() + "/shorten",
+			URL:      args[0],
+			Private:  private,
+			Expires:  expires,
+		})
+	}
+
 	resp, err := client.ShortenURL(args[0], private, expires)
 	if err != nil {
-		return fmt.Errorf("error shortening URL: %w", err)
+		return wrapAPIError("error shortening URL", err)
 	}
 
 	if !resp.Success {
 		return fmt.Errorf("error shortening URL: %s", resp.Error)
 	}
 
+	if copyURL {
+		copyToClipboard(cmd, resp.URL)
+	}
+
+	if field != "" {
+		return printField(cmd, resp, field)
+	}
+
+	if tmpl != nil {
+		return printTemplate(cmd, tmpl, resp)
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, resp)
+	}
+
 	fmt.Fprintln(cmd.OutOrStdout(), resp.URL)
-	if resp.DeleteURL != "" {
+	if resp.DeleteURL != "" && !quietOutput() {
 		fmt.Fprintln(cmd.OutOrStdout(), "Delete URL:", resp.DeleteURL)
 	}
 
+	if showQR {
+		return printQR(cmd, resp.URL)
+	}
+
+	return nil
+}
+
+// validateShortenURL catches malformed URLs locally instead of leaving them
+// to a confusing server error. Only http and https URLs with a host are
+// accepted unless anyScheme is set.
+func validateShortenURL(raw string, anyScheme bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return usageErrorf("invalid URL: %s", raw)
+	}
+
+	if anyScheme {
+		if u.Scheme == "" {
+			return usageErrorf("invalid URL: %s (missing scheme)", raw)
+		}
+		return nil
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return usageErrorf("invalid URL: %s (only http and https URLs can be shortened; use --allow-any-scheme to override)", raw)
+	}
+	if u.Host == "" {
+		return usageErrorf("invalid URL: %s (missing host)", raw)
+	}
 	return nil
 }
 
 func NewListCmd() *cobra.Command {
 	var page int
 	var limit int
+	var showRaw bool
+	var showDownload bool
+	var showDelete bool
+	var all bool
+	var order string
+	var sort string
+	var filter string
+	var table bool
 
 	cmd := &cobra.Command{
-		Use:   "list [pastes|urls]",
+		Use:   "list [pastes|urls|all]",
 		Short: "List your pastes or shortened URLs",
 		Args:  cobra.ExactArgs(1),
 		RunE:  List,
 	}
 
 	cmd.Flags().IntVar(&page, "page", 1, "Page number")
-	cmd.Flags().IntVar(&limit, "per-page", 10, "Number of items per page")
+	cmd.Flags().IntVar(&limit, "per-page", 10, "Number of items per page (0 for the server default)")
+	cmd.Flags().BoolVar(&showRaw, "show-raw", false, "Include raw URLs in paste listings")
+	cmd.Flags().BoolVar(&showDownload, "show-download", false, "Include download URLs in paste listings")
+	cmd.Flags().BoolVar(&showDelete, "show-delete", false, "Include delete URLs in paste listings")
+	cmd.Flags().BoolVar(&all, "all", false, "Fetch every page instead of a single one")
+	cmd.Flags().StringVar(&order, "order", "desc", "Sort direction: asc or desc")
+	cmd.Flags().StringVar(&sort, "sort", "", "Field to sort by: "+strings.Join(sortFields, ", ")+" (clicks applies to URLs)")
+	cmd.Flags().StringVar(&filter, "filter", "", "Only show items whose filename or URL contains this text")
+	cmd.Flags().BoolVar(&table, "table", false, "Show results as a table")
+	cmd.Flags().Bool("prompt", false, "Offer to show the next page when there is one (terminals only)")
+	cmd.Flags().String("format", "", "Print each item with a Go template (e.g. '{{.id}} {{.url}}')")
+	cmd.Flags().Bool("jsonl", false, "Print one JSON object per line for each item")
+	cmd.Flags().String("since", "", "Only show items created on or after this date (YYYY-MM-DD or RFC3339)")
+	cmd.Flags().String("until", "", "Only show items created on or before this date (YYYY-MM-DD or RFC3339)")
 
 	return cmd
 }
@@ -148,96 +1046,442 @@ func List(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	showRaw, err := cmd.Flags().GetBool("show-raw")
+	if err != nil {
+		return err
+	}
+
+	showDownload, err := cmd.Flags().GetBool("show-download")
+	if err != nil {
+		return err
+	}
+
+	showDelete, err := cmd.Flags().GetBool("show-delete")
+	if err != nil {
+		return err
+	}
+
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+
+	order, err := cmd.Flags().GetString("order")
+	if err != nil {
+		return err
+	}
+	if order != "asc" && order != "desc" {
+		return usageErrorf("invalid order %q: must be 'asc' or 'desc'", order)
+	}
+
+	sort, err := cmd.Flags().GetString("sort")
+	if err != nil {
+		return err
+	}
+	if err := validateSort(sort); err != nil {
+		return err
+	}
+
+	filter, err := cmd.Flags().GetString("filter")
+	if err != nil {
+		return err
+	}
+
+	table, err := cmd.Flags().GetBool("table")
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := formatFlag(cmd)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil && table {
+		return usageErrorf("--format can't be combined with --table")
+	}
+
+	jsonl, err := cmd.Flags().GetBool("jsonl")
+	if err != nil {
+		return err
+	}
+	if jsonl && (jsonOutput() || table || tmpl != nil) {
+		return usageErrorf("--jsonl can't be combined with --json, --table or --format")
+	}
+
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return err
+	}
+
+	until, err := cmd.Flags().GetString("until")
+	if err != nil {
+		return err
+	}
+
+	dates, err := parseDateRange(since, until)
+	if err != nil {
+		return err
+	}
+
+	opts, err := listOptions(cmd, page, perPage, order)
+	if err != nil {
+		return err
+	}
+	opts.Sort = sort
+	pasteURLs := pasteURLOptions{Raw: showRaw, Download: showDownload, Delete: showDelete}
+
 	switch listType {
 	case "pastes":
-		resp, err := client.ListPastes(page, perPage)
+		var resp *api.ListResponse[api.PasteListItem]
+		if all {
+			resp, err = fetchAll(client.ListPastes, opts)
+		} else {
+			resp, err = client.ListPastes(opts)
+		}
 		if err != nil {
-			return fmt.Errorf("error listing pastes: %w", err)
+			return wrapAPIError("error listing pastes", err)
 		}
 
 		if !resp.Success {
 			return fmt.Errorf("error listing pastes: %s", resp.Error)
 		}
 
+		received := len(resp.Data.Items)
+		resp.Data.Items = filterByDate(resp.Data.Items, dates, func(item api.PasteListItem) string {
+			return item.CreatedAt
+		})
+
+		fetched := len(resp.Data.Items)
+		if filter != "" {
+			resp.Data.Items = filterItems(resp.Data.Items, filter, func(item api.PasteListItem) []string {
+				return []string{item.Filename, item.URL}
+			})
+		}
+
+		if jsonOutput() {
+			return printJSON(cmd, resp)
+		}
+		if jsonl {
+			return printJSONLines(cmd, resp.Data.Items)
+		}
+		if tmpl != nil {
+			return printTemplateEach(cmd, tmpl, resp.Data.Items)
+		}
+
 		fmt.Fprintln(cmd.OutOrStdout(), theme.Title.Render("Your Pastes"))
-		for _, item := range resp.Data.Items {
-			createdAt, err := time.Parse(time.RFC3339, item.CreatedAt)
-			if err != nil {
-				createdAt = time.Time{}
+		if filter != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), theme.Subtitle.Render(fmt.Sprintf("%d of %d pastes match %q", len(resp.Data.Items), fetched, filter)))
+		}
+
+		if table {
+			renderPasteTable(cmd.OutOrStdout(), resp.Data.Items)
+		} else {
+			for _, item := range resp.Data.Items {
+				printPasteItem(cmd.OutOrStdout(), item, pasteURLs)
+				fmt.Fprintln(cmd.OutOrStdout())
 			}
+		}
 
-			fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("ID", item.Id))
-			fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Filename", item.Filename))
-			fmt.Fprintf(cmd.OutOrStdout(), "%s %d bytes\n", theme.ListItemKey.Render("Size:"), item.Size)
-			fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Created", createdAt.Format(time.RFC3339)))
-			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("URL:"), theme.FormatURL(item.URL))
-			fmt.Fprintln(cmd.OutOrStdout())
+		if !all && !quietOutput() {
+			info := newPageInfo(opts.Page, opts.PerPage, resp.Data.Total, resp.Data.Limit, received)
+			printPageFooter(cmd, args, info)
+			if showNextPage(cmd, info) {
+				_ = cmd.Flags().Set("page", strconv.Itoa(info.Page+1))
+				return List(cmd, args)
+			}
 		}
 
 	case "urls":
-		resp, err := client.ListURLs(page, perPage)
+		var resp *api.ListResponse[api.URLListItem]
+		if all {
+			resp, err = fetchAll(client.ListURLs, opts)
+		} else {
+			resp, err = client.ListURLs(opts)
+		}
 		if err != nil {
-			return fmt.Errorf("error listing URLs: %w", err)
+			return wrapAPIError("error listing URLs", err)
 		}
 
 		if !resp.Success {
 			return fmt.Errorf("error listing URLs: %s", resp.Error)
 		}
 
+		received := len(resp.Data.Items)
+		resp.Data.Items = filterByDate(resp.Data.Items, dates, func(item api.URLListItem) string {
+			return item.CreatedAt
+		})
+
+		fetched := len(resp.Data.Items)
+		if filter != "" {
+			resp.Data.Items = filterItems(resp.Data.Items, filter, func(item api.URLListItem) []string {
+				return []string{item.URL, item.ShortURL, item.OriginalURL}
+			})
+		}
+
+		if jsonOutput() {
+			return printJSON(cmd, resp)
+		}
+		if jsonl {
+			return printJSONLines(cmd, resp.Data.Items)
+		}
+		if tmpl != nil {
+			return printTemplateEach(cmd, tmpl, resp.Data.Items)
+		}
+
 		fmt.Fprintln(cmd.OutOrStdout(), theme.Title.Render("Your Shortened URLs"))
-		for _, item := range resp.Data.Items {
-			createdAt, err := time.Parse(time.RFC3339, item.CreatedAt)
-			if err != nil {
-				createdAt = time.Time{}
+		if filter != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), theme.Subtitle.Render(fmt.Sprintf("%d of %d URLs match %q", len(resp.Data.Items), fetched, filter)))
+		}
+
+		if table {
+			renderURLTable(cmd.OutOrStdout(), resp.Data.Items)
+		} else {
+			for _, item := range resp.Data.Items {
+				printURLItem(cmd.OutOrStdout(), item)
+				fmt.Fprintln(cmd.OutOrStdout())
 			}
+		}
 
-			fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("ID", item.Id))
-			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("Short URL:"), theme.FormatURL(item.ShortURL))
-			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("Original URL:"), theme.FormatURL(item.OriginalURL))
-			fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Created", createdAt.Format(time.RFC3339)))
-			fmt.Fprintln(cmd.OutOrStdout())
+		if !all && !quietOutput() {
+			info := newPageInfo(opts.Page, opts.PerPage, resp.Data.Total, resp.Data.Limit, received)
+			printPageFooter(cmd, args, info)
+			if showNextPage(cmd, info) {
+				_ = cmd.Flags().Set("page", strconv.Itoa(info.Page+1))
+				return List(cmd, args)
+			}
 		}
 
+	case "all":
+		return listAll(cmd, opts, all, filter, dates, table, pasteURLs)
+
 	default:
-		return fmt.Errorf("%s", theme.FormatError("Invalid list type. Must be 'pastes' or 'urls'"))
+		return usageErrorf("%s", theme.FormatError("Invalid list type. Must be 'pastes', 'urls' or 'all'"))
 	}
 
 	return nil
 }
 
+// pasteURLOptions selects the optional URLs shown for each paste.
+type pasteURLOptions struct {
+	Raw      bool
+	Download bool
+	Delete   bool
+}
+
+func printPasteItem(w io.Writer, item api.PasteListItem, urls pasteURLOptions) {
+	createdAt, err := time.Parse(time.RFC3339, item.CreatedAt)
+	if err != nil {
+		createdAt = time.Time{}
+	}
+
+	fmt.Fprintln(w, theme.FormatKeyValue("ID", item.Id))
+	fmt.Fprintln(w, theme.FormatKeyValue("Filename", item.Filename))
+	if item.Title != "" {
+		fmt.Fprintln(w, theme.FormatKeyValue("Title", item.Title))
+	}
+	fmt.Fprintf(w, "%s %d bytes\n", theme.ListItemKey.Render("Size:"), item.Size)
+	fmt.Fprintln(w, theme.FormatKeyValue("Created", formatTime(createdAt, time.RFC3339)))
+	fmt.Fprintf(w, "%s %s\n", theme.ListItemKey.Render("URL:"), theme.FormatURL(item.URL))
+	if urls.Raw && item.RawURL != "" {
+		fmt.Fprintf(w, "%s %s\n", theme.ListItemKey.Render("Raw URL:"), theme.FormatURL(item.RawURL))
+	}
+	if urls.Download && item.DownloadURL != "" {
+		fmt.Fprintf(w, "%s %s\n", theme.ListItemKey.Render("Download URL:"), theme.FormatURL(item.DownloadURL))
+	}
+	if urls.Delete && item.DeleteURL != "" {
+		fmt.Fprintf(w, "%s %s\n", theme.ListItemKey.Render("Delete URL:"), theme.FormatDeleteURL(item.DeleteURL))
+	}
+}
+
+func printURLItem(w io.Writer, item api.URLListItem) {
+	createdAt, err := time.Parse(time.RFC3339, item.CreatedAt)
+	if err != nil {
+		createdAt = time.Time{}
+	}
+
+	fmt.Fprintln(w, theme.FormatKeyValue("ID", item.Id))
+	fmt.Fprintf(w, "%s %s\n", theme.ListItemKey.Render("Short URL:"), theme.FormatURL(item.ShortURL))
+	fmt.Fprintf(w, "%s %s\n", theme.ListItemKey.Render("Original URL:"), theme.FormatURL(item.OriginalURL))
+	fmt.Fprintln(w, theme.FormatKeyValue("Created", formatTime(createdAt, time.RFC3339)))
+}
+
 func NewDeleteCmd() *cobra.Command {
+	var yes bool
+	var byURL bool
+
 	cmd := &cobra.Command{
-		Use:   "delete [id]",
-		Short: "Delete a paste or shortened URL",
-		Args:  cobra.ExactArgs(1),
-		RunE:  Delete,
+		Use:   "delete [id...]",
+		Short: "Delete pastes or shortened URLs",
+		Long: `Delete pastes or shortened URLs by ID.
+
+With --by-url, give their URLs instead (e.g. https://0x45.st/abc123). They
+are looked up among your pastes and URLs to find the ID to delete them by.`,
+		Example: "  0x45 delete abc123\n  0x45 delete --by-url https://0x45.st/abc123",
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    Delete,
 	}
 
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+	cmd.Flags().BoolVar(&byURL, "by-url", false, "Take paste or short URLs instead of IDs")
+
 	return cmd
 }
 
+// deleteResult reports the outcome of deleting one ID.
+type deleteResult struct {
+	Id      string `json:"id"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
 func Delete(cmd *cobra.Command, args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("expected 1 argument, got %d", len(args))
+	if len(args) == 0 {
+		return usageErrorf("expected at least 1 argument")
+	}
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
 	}
 
-	resp, err := client.Delete(args[0])
+	byURL, err := cmd.Flags().GetBool("by-url")
 	if err != nil {
-		return fmt.Errorf("error deleting content: %w", err)
+		return err
 	}
 
-	if !resp.Success {
-		return fmt.Errorf("error deleting content: %s", resp.Error)
+	if !yes && !canPrompt(cmd) {
+		return usageErrorf("refusing to delete without confirmation: pass --yes when not running interactively")
+	}
+
+	if byURL {
+		if args, err = deleteIDsByURL(args); err != nil {
+			return err
+		}
+	}
+
+	if !yes {
+		descriptions := describeContent(args)
+		fmt.Fprintln(cmd.ErrOrStderr(), "About to delete:")
+		for _, id := range args {
+			if desc := descriptions[id]; desc != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  %s (%s)\n", id, desc)
+			} else {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", id)
+			}
+		}
+		if !confirm(cmd, "Are you sure?") {
+			fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatWarning("Aborted"))
+			return nil
+		}
+	}
+
+	if len(args) == 1 {
+		logging.Infof("Deleting %s", args[0])
+		resp, err := client.Delete(args[0])
+		if err != nil {
+			return wrapAPIError("error deleting content", err)
+		}
+
+		if !resp.Success {
+			return fmt.Errorf("error deleting content: %s", resp.Error)
+		}
+
+		if jsonOutput() {
+			return printJSON(cmd, resp)
+		}
+
+		if !quietOutput() {
+			fmt.Fprintln(cmd.OutOrStdout(), resp.Message)
+		}
+		return nil
+	}
+
+	// A failure on one ID doesn't stop the rest of the batch.
+	results := make([]deleteResult, 0, len(args))
+	failed := 0
+	for _, id := range args {
+		logging.Infof("Deleting %s", id)
+		result := deleteResult{Id: id}
+		resp, err := client.Delete(id)
+		switch {
+		case err != nil:
+			result.Error = wrapAPIError("error deleting content", err).Error()
+		case !resp.Success:
+			result.Error = "error deleting content: " + resp.Error
+		default:
+			result.Success = true
+			result.Message = resp.Message
+		}
+		if !result.Success {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if jsonOutput() {
+		if err := printJSON(cmd, results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			switch {
+			case !result.Success && quietOutput():
+				fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatError(fmt.Sprintf("%s: %s", result.Id, result.Error)))
+			case !result.Success:
+				fmt.Fprintln(cmd.OutOrStdout(), theme.FormatError(fmt.Sprintf("%s: %s", result.Id, result.Error)))
+			case !quietOutput():
+				fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("%s: %s", result.Id, result.Message)))
+			}
+		}
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), resp.Message)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deletions failed", failed, len(args))
+	}
 	return nil
 }
 
+// describeContent looks ids up among the most recent pastes and URLs so the
+// confirmation prompt can show what is about to be deleted. IDs that can't be
+// resolved are left out of the result.
+func describeContent(ids []string) map[string]string {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	descriptions := make(map[string]string)
+	opts := api.ListOptions{Page: 1, PerPage: maxPerPage}
+
+	if pastes, err := client.ListPastes(opts); err == nil {
+		for _, item := range pastes.Data.Items {
+			if wanted[item.Id] {
+				descriptions[item.Id] = fmt.Sprintf("%s, %s", item.Filename, item.URL)
+			}
+		}
+	}
+
+	if len(descriptions) < len(wanted) {
+		if urls, err := client.ListURLs(opts); err == nil {
+			for _, item := range urls.Data.Items {
+				if wanted[item.Id] && descriptions[item.Id] == "" {
+					descriptions[item.Id] = fmt.Sprintf("%s -> %s", item.ShortURL, item.OriginalURL)
+				}
+			}
+		}
+	}
+
+	return descriptions
+}
+
 func NewConfigCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Manage configuration",
+		// Config commands must work before an API key has been set, and
+		// with settings broken enough that no request could be made.
+		Annotations: map[string]string{apiKeyAnnotation: apiKeyUnused},
 	}
 
 	getCmd := &cobra.Command{
@@ -258,27 +1502,60 @@ func NewConfigCmd() *cobra.Command {
 	setCmd := &cobra.Command{
 		Use:   "set [key] [value]",
 		Short: "Set a config value",
-		Args:  cobra.ExactArgs(2),
+		Long: `Set a config value.
+
+Values are stored as strings unless --type says otherwise, e.g. --type bool
+for copy_on_upload, so the config file holds a real boolean or number.
+
+With --stdin the value is read from stdin instead, or typed at a prompt
+without echo, and isn't printed back. Use this for secrets such as api_key
+so they stay out of shell history and process listings.`,
+		Example: "  0x45 config set api_url https://0x45.st\n  0x45 config set copy_on_upload true --type bool\n  echo \"$KEY\" | 0x45 config set api_key --stdin",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			viper.Set(args[0], args[1])
-			if err := viper.WriteConfig(); err != nil {
-				if os.IsNotExist(err) {
-					configDir := filepath.Dir(viper.ConfigFileUsed())
-					if err := os.MkdirAll(configDir, 0755); err != nil {
-						return fmt.Errorf(theme.FormatError("Could not create config directory: %v"), err)
-					}
-					if err := viper.WriteConfigAs(viper.ConfigFileUsed()); err != nil {
-						return fmt.Errorf(theme.FormatError("Could not write config file: %v"), err)
-					}
-				} else {
-					return fmt.Errorf(theme.FormatError("Could not write config file: %v"), err)
+			kind, err := cmd.Flags().GetString("type")
+			if err != nil {
+				return err
+			}
+			if err := checkConfigType(kind); err != nil {
+				return err
+			}
+
+			if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+				raw, err := ReadSecret(cmd, args[0]+": ")
+				if err != nil {
+					return fmt.Errorf("error reading %s: %w", args[0], err)
+				}
+				value, err := coerceConfigValue(raw, kind)
+				if err != nil {
+					return err
 				}
+				if err := writeConfigValue(args[0], value); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("Config value '%s' set", args[0])))
+				return nil
+			}
+
+			value, err := coerceConfigValue(args[1], kind)
+			if err != nil {
+				return err
+			}
+			if err := writeConfigValue(args[0], value); err != nil {
+				return err
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), theme.FormatSuccess("Config value '%s' set to '%s'\n"), args[0], args[1])
+			fmt.Fprintf(cmd.OutOrStdout(), theme.FormatSuccess("Config value '%s' set to '%v'\n"), args[0], value)
 			return nil
 		},
 	}
+	setCmd.Flags().Bool("stdin", false, "Read the value from stdin, or a prompt without echo, and don't print it")
+	setCmd.Flags().String("type", "string", "Type to store the value as: "+strings.Join(configValueTypes, ", "))
 
-	cmd.AddCommand(getCmd, setCmd)
+	cmd.AddCommand(getCmd, setCmd, newConfigListCmd(), newConfigEditCmd(), newConfigPathCmd(), newConfigMigrateCmd(), newConfigProfileCmd(), newConfigThemeCmd(), newConfigValidateCmd())
 	return cmd
 }
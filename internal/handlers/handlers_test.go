@@ -3,16 +3,22 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/watzon/0x45-cli/internal/client"
-	"github.com/watzon/0x45-cli/pkg/api/paste69"
+	api "github.com/watzon/0x45-cli/pkg/client"
 )
 
 func setupTestServer() *httptest.Server {
@@ -23,10 +29,11 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.UploadResponse{
+			resp := api.UploadResponse{
 				Success:   true,
 				URL:       "https://0x45.st/abc123",
 				DeleteURL: "https://0x45.st/delete/abc123",
+				MimeType:  "text/plain",
 			}
 			if err := json.NewEncoder(w).Encode(resp); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -37,7 +44,7 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.ShortenResponse{
+			resp := api.ShortenResponse{
 				Success:   true,
 				URL:       "https://0x45.st/abc123",
 				DeleteURL: "https://0x45.st/delete/abc123",
@@ -51,16 +58,17 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.ListResponse[paste69.PasteListItem]{
+			resp := api.ListResponse[api.PasteListItem]{
 				Success: true,
 			}
-			resp.Data.Items = []paste69.PasteListItem{
+			resp.Data.Items = []api.PasteListItem{
 				{
 					Id:        "abc123",
 					Filename:  "test.txt",
 					Size:      123,
 					CreatedAt: "2023-01-01T00:00:00Z",
 					URL:       "https://0x45.st/abc123",
+					RawURL:    "https://0x45.st/abc123/raw",
 				},
 			}
 			if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -72,10 +80,10 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.ListResponse[paste69.URLListItem]{
+			resp := api.ListResponse[api.URLListItem]{
 				Success: true,
 			}
-			resp.Data.Items = []paste69.URLListItem{
+			resp.Data.Items = []api.URLListItem{
 				{
 					Id:          "abc123",
 					URL:         "https://0x45.st/abc123",
@@ -88,12 +96,51 @@ func setupTestServer() *httptest.Server {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+		case "/urls/abc123/stats":
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			resp := api.URLStatsResponse{
+				Success:     true,
+				Id:          "abc123",
+				ShortURL:    "https://0x45.st/abc123",
+				OriginalURL: "https://example.com",
+				Clicks:      42,
+			}
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		case "/urls/abc123/expiration":
+			if r.Method != http.MethodPut {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			expiresAt := "2030-01-02T00:00:00Z"
+			resp := api.UpdateExpirationResponse{
+				Success:   true,
+				Id:        "abc123",
+				ShortURL:  "https://0x45.st/abc123",
+				ExpiresAt: &expiresAt,
+			}
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		case "/abc123/raw":
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Disposition", `inline; filename="test.txt"`)
+			_, _ = w.Write([]byte("test content"))
 		case "/delete/abc123":
 			if r.Method != http.MethodDelete {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				return
 			}
-			resp := paste69.GenericResponse{
+			resp := api.GenericResponse{
 				Success: true,
 				Message: "Deleted successfully",
 			}
@@ -129,9 +176,9 @@ func TestUploadHandler(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cmd := &cobra.Command{}
-	cmd.Flags().Bool("private", true, "")
-	cmd.Flags().String("expires", "24h", "")
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("private", "true")
+	_ = cmd.Flags().Set("expires", "24h")
 
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
@@ -145,6 +192,9 @@ func TestUploadHandler(t *testing.T) {
 	if !strings.Contains(output, "https://0x45.st/abc123") {
 		t.Error("Expected output to contain URL")
 	}
+	if !strings.Contains(output, "Type: text/plain") {
+		t.Error("Expected output to contain the detected content type")
+	}
 }
 
 func TestShortenHandler(t *testing.T) {
@@ -155,9 +205,9 @@ func TestShortenHandler(t *testing.T) {
 	viper.Set("api_key", "test-key")
 	client.Initialize()
 
-	cmd := &cobra.Command{}
-	cmd.Flags().Bool("private", true, "")
-	cmd.Flags().String("expires", "24h", "")
+	cmd := NewShortenCmd()
+	_ = cmd.Flags().Set("private", "true")
+	_ = cmd.Flags().Set("expires", "24h")
 
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
@@ -181,9 +231,7 @@ func TestListPastesHandler(t *testing.T) {
 	viper.Set("api_key", "test-key")
 	client.Initialize()
 
-	cmd := &cobra.Command{}
-	cmd.Flags().Int("page", 1, "")
-	cmd.Flags().Int("per-page", 10, "")
+	cmd := NewListCmd()
 
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
@@ -207,9 +255,7 @@ func TestListURLsHandler(t *testing.T) {
 	viper.Set("api_key", "test-key")
 	client.Initialize()
 
-	cmd := &cobra.Command{}
-	cmd.Flags().Int("page", 1, "")
-	cmd.Flags().Int("per-page", 10, "")
+	cmd := NewListCmd()
 
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
@@ -233,9 +279,10 @@ func TestDeleteHandler(t *testing.T) {
 	viper.Set("api_key", "test-key")
 	client.Initialize()
 
-	cmd := &cobra.Command{}
+	cmd := NewDeleteCmd()
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("yes", "true")
 
 	err := Delete(cmd, []string{"abc123"})
 	if err != nil {
@@ -247,3 +294,1551 @@ func TestDeleteHandler(t *testing.T) {
 		t.Error("Expected output to contain success message")
 	}
 }
+
+func TestStatsHandler(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewStatsCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	err := Stats(cmd, []string{"abc123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "42") {
+		t.Error("Expected output to contain click count")
+	}
+	if !strings.Contains(output, "no clicks yet") {
+		t.Error("Expected output to mention no clicks yet")
+	}
+
+	err = Stats(cmd, []string{"missing"})
+	if err == nil || !strings.Contains(err.Error(), "URL not found") {
+		t.Errorf("Expected URL not found error, got %v", err)
+	}
+}
+
+func TestRenewHandler(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewRenewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := Renew(cmd, []string{"abc123"}); err == nil || !strings.Contains(err.Error(), "--expires") {
+		t.Errorf("Expected missing --expires error, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("expires", "soon")
+	if err := Renew(cmd, []string{"abc123"}); err == nil || !strings.Contains(err.Error(), "invalid expiry duration") {
+		t.Errorf("Expected invalid expiry duration error, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("expires", "720h")
+	if err := Renew(cmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "2030-01-02") {
+		t.Error("Expected output to contain new expiry date")
+	}
+	if !strings.Contains(output, "https://0x45.st/abc123") {
+		t.Error("Expected output to contain short URL")
+	}
+}
+
+func TestUploadHandlerJSON(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	viper.Set("json", true)
+	defer viper.Set("json", false)
+	client.Initialize()
+
+	tmpfile, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte("test content")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := Upload(cmd, []string{tmpfile.Name()}); err != nil {
+		t.Fatal(err)
+	}
+
+	var resp api.UploadResponse
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected valid JSON output, got %q: %v", buf.String(), err)
+	}
+	if resp.URL != "https://0x45.st/abc123" {
+		t.Errorf("Expected URL to be https://0x45.st/abc123, got %s", resp.URL)
+	}
+}
+
+func TestPrintJSONStyle(t *testing.T) {
+	defer viper.Set("json_pretty", false)
+	defer viper.Set("json_compact", false)
+
+	render := func() string {
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := printJSON(cmd, map[string]string{"url": "https://0x45.st/abc123"}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	compact := "{\"url\":\"https://0x45.st/abc123\"}\n"
+	if got := render(); got != compact {
+		t.Errorf("Expected compact JSON when not writing to a terminal, got %q", got)
+	}
+
+	viper.Set("json_pretty", true)
+	if got := render(); !strings.Contains(got, "\n  \"url\"") {
+		t.Errorf("Expected indented JSON with json_pretty, got %q", got)
+	}
+
+	viper.Set("json_compact", true)
+	if got := render(); got != compact {
+		t.Errorf("Expected json_compact to win, got %q", got)
+	}
+}
+
+func TestShortenHandlerCopy(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	var copied string
+	oldWrite := writeClipboard
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	defer func() { writeClipboard = oldWrite }()
+
+	cmd := NewShortenCmd()
+	_ = cmd.Flags().Set("copy", "true")
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+
+	if err := Shorten(cmd, []string{"https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if copied != "https://0x45.st/abc123" {
+		t.Errorf("Expected URL to be copied, got %q", copied)
+	}
+}
+
+func TestQRHandler(t *testing.T) {
+	viper.Set("api_url", "https://0x45.st")
+
+	cmd := NewQRCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := QR(cmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "https://0x45.st/abc123\n") {
+		t.Errorf("Expected output to start with resolved URL, got %q", output)
+	}
+	if !strings.Contains(output, "█") {
+		t.Error("Expected output to contain a QR code")
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	var out bytes.Buffer
+	progress := newProgressReader(strings.NewReader("test content"), 12, &out)
+
+	data, err := io.ReadAll(progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	progress.Finish()
+
+	if string(data) != "test content" {
+		t.Errorf("Expected body to pass through unchanged, got %q", data)
+	}
+	if !strings.Contains(out.String(), "100%") {
+		t.Errorf("Expected progress output to reach 100%%, got %q", out.String())
+	}
+}
+
+func TestGetHandler(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	dir := t.TempDir()
+
+	cmd := NewGetCmd()
+	_ = cmd.Flags().Set("output", dir)
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := Get(cmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "test.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "test content" {
+		t.Errorf("Expected downloaded content, got %q", data)
+	}
+	if !strings.Contains(buf.String(), "Wrote 12 bytes") {
+		t.Errorf("Expected byte count in output, got %s", buf.String())
+	}
+
+	if err := Get(cmd, []string{"abc123"}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected existing file error, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("force", "true")
+	if err := Get(cmd, []string{"abc123"}); err != nil {
+		t.Errorf("Expected --force to overwrite, got %v", err)
+	}
+
+	if err := Get(cmd, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "paste not found") {
+		t.Errorf("Expected paste not found error, got %v", err)
+	}
+
+	viper.Set("quiet", true)
+	defer viper.Set("quiet", false)
+	for _, output := range []string{dir, ""} {
+		cmd := NewGetCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		_ = cmd.Flags().Set("output", output)
+		_ = cmd.Flags().Set("force", "true")
+		if err := Get(cmd, []string{"abc123"}); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(buf.String(), "Wrote") {
+			t.Errorf("Expected no status line with --quiet, got %q", buf.String())
+		}
+	}
+}
+
+func TestListPastesHandlerShowRaw(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := List(cmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "abc123/raw") {
+		t.Error("Expected raw URL to be hidden by default")
+	}
+
+	buf.Reset()
+	_ = cmd.Flags().Set("show-raw", "true")
+	if err := List(cmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "https://0x45.st/abc123/raw") {
+		t.Error("Expected output to contain raw URL")
+	}
+}
+
+func TestListPastesHandlerAll(t *testing.T) {
+	const total = 5
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+
+		resp := api.ListResponse[api.PasteListItem]{Success: true}
+		resp.Data.Total = total
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			resp.Data.Items = append(resp.Data.Items, api.PasteListItem{
+				Id:       strconv.Itoa(i),
+				Filename: "file" + strconv.Itoa(i) + ".txt",
+			})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	_ = cmd.Flags().Set("all", "true")
+	_ = cmd.Flags().Set("per-page", "2")
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := List(cmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < total; i++ {
+		if !strings.Contains(buf.String(), "file"+strconv.Itoa(i)+".txt") {
+			t.Errorf("Expected output to contain file%d.txt", i)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests)
+	}
+}
+
+func TestListHandlerOrder(t *testing.T) {
+	var order string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = r.URL.Query().Get("order")
+		_ = json.NewEncoder(w).Encode(api.ListResponse[api.URLListItem]{Success: true})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	cmd.SetOut(&bytes.Buffer{})
+
+	if err := List(cmd, []string{"urls"}); err != nil {
+		t.Fatal(err)
+	}
+	if order != "desc" {
+		t.Errorf("Expected default order to be desc, got %q", order)
+	}
+
+	_ = cmd.Flags().Set("order", "asc")
+	if err := List(cmd, []string{"urls"}); err != nil {
+		t.Fatal(err)
+	}
+	if order != "asc" {
+		t.Errorf("Expected order to be asc, got %q", order)
+	}
+
+	_ = cmd.Flags().Set("order", "sideways")
+	if err := List(cmd, []string{"urls"}); err == nil || !strings.Contains(err.Error(), "invalid order") {
+		t.Errorf("Expected invalid order error, got %v", err)
+	}
+}
+
+func TestListHandlerSort(t *testing.T) {
+	var sort string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sort = r.URL.Query().Get("sort")
+		_ = json.NewEncoder(w).Encode(api.ListResponse[api.URLListItem]{Success: true})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("sort", "clicks")
+	if err := List(cmd, []string{"urls"}); err != nil {
+		t.Fatal(err)
+	}
+	if sort != "clicks" {
+		t.Errorf("Expected sort to be clicks, got %q", sort)
+	}
+
+	sort = ""
+	_ = cmd.Flags().Set("sort", "size")
+	var usageErr *UsageError
+	err := List(cmd, []string{"urls"})
+	if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "created_at, expires_at, clicks") {
+		t.Errorf("Expected a usage error listing the sort fields, got %v", err)
+	}
+	if sort != "" {
+		t.Error("Expected no request for an invalid sort field")
+	}
+}
+
+func TestListHandlerPerPage(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_ = json.NewEncoder(w).Encode(api.ListResponse[api.URLListItem]{Success: true})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+
+	if err := List(cmd, []string{"urls"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := query.Get("per_page"); got != "10" {
+		t.Errorf("Expected per_page=10 by default, got %q", got)
+	}
+
+	_ = cmd.Flags().Set("per-page", "0")
+	if err := List(cmd, []string{"urls"}); err != nil {
+		t.Fatal(err)
+	}
+	if query.Has("per_page") {
+		t.Errorf("Expected per_page to be left to the server, got %q", query.Get("per_page"))
+	}
+
+	_ = cmd.Flags().Set("per-page", "500")
+	if err := List(cmd, []string{"urls"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := query.Get("per_page"); got != "500" {
+		t.Errorf("Expected per_page=500, got %q", got)
+	}
+	if !strings.Contains(stderr.String(), "above the server maximum of 100") {
+		t.Errorf("Expected a warning about the server maximum, got %q", stderr.String())
+	}
+
+	var usageErr *UsageError
+	_ = cmd.Flags().Set("per-page", "-1")
+	if err := List(cmd, []string{"urls"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for a negative per-page, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("per-page", "10")
+	_ = cmd.Flags().Set("page", "0")
+	if err := List(cmd, []string{"urls"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for page 0, got %v", err)
+	}
+}
+
+func TestListPastesHandlerFilter(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	_ = cmd.Flags().Set("filter", "TEST")
+	if err := List(cmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "test.txt") || !strings.Contains(buf.String(), "1 of 1 pastes match") {
+		t.Errorf("Expected case-insensitive match, got %s", buf.String())
+	}
+
+	buf.Reset()
+	_ = cmd.Flags().Set("filter", "nomatch")
+	if err := List(cmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "test.txt") || !strings.Contains(buf.String(), "0 of 1 pastes match") {
+		t.Errorf("Expected no matches, got %s", buf.String())
+	}
+}
+
+func TestListHandlerDateRange(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	// The test server's items were created at 2023-01-01T00:00:00Z.
+	tests := []struct {
+		since, until string
+		listed       bool
+		wantErr      bool
+	}{
+		{since: "2022-12-31", listed: true},
+		{since: "2023-01-02", listed: false},
+		{until: "2023-01-01", listed: true},
+		{until: "2022-12-31", listed: false},
+		{since: "2022-12-01", until: "2023-02-01T00:00:00Z", listed: true},
+		{since: "last week", wantErr: true},
+		{since: "2023-02-01", until: "2023-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		for _, listType := range []string{"pastes", "urls"} {
+			cmd := NewListCmd()
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			_ = cmd.Flags().Set("since", tt.since)
+			_ = cmd.Flags().Set("until", tt.until)
+
+			err := List(cmd, []string{listType})
+			if tt.wantErr {
+				var usageErr *UsageError
+				if !errors.As(err, &usageErr) {
+					t.Errorf("since %q until %q: expected usage error, got %v", tt.since, tt.until, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if listed := strings.Contains(buf.String(), "abc123"); listed != tt.listed {
+				t.Errorf("%s since %q until %q: listed = %v, want %v", listType, tt.since, tt.until, listed, tt.listed)
+			}
+		}
+	}
+}
+
+func TestUploadHandlerPrivateRequiresKey(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "")
+	defer viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	tmpfile, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("private", "true")
+	cmd.SetOut(&bytes.Buffer{})
+
+	err = Upload(cmd, []string{tmpfile.Name()})
+	if err == nil || !strings.Contains(err.Error(), "private uploads require an API key") {
+		t.Errorf("Expected missing API key error, got %v", err)
+	}
+}
+
+func TestUploadHandlerMime(t *testing.T) {
+	var contentType, mimeParam string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		mimeParam = r.URL.Query().Get("mime")
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	tmpfile, err := os.CreateTemp("", "test*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte("test content")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("mime", "text/markdown")
+
+	if err := Upload(cmd, []string{tmpfile.Name()}); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "text/markdown" || mimeParam != "text/markdown" {
+		t.Errorf("Expected text/markdown content type, got header %q and param %q", contentType, mimeParam)
+	}
+
+	_ = cmd.Flags().Set("mime", "not a mime type")
+	if err := Upload(cmd, []string{tmpfile.Name()}); err == nil || !strings.Contains(err.Error(), "invalid MIME type") {
+		t.Errorf("Expected invalid MIME type error, got %v", err)
+	}
+}
+
+func TestUploadHandlerEmpty(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	tmpfile, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+
+	if err := Upload(cmd, []string{tmpfile.Name()}); err == nil || !strings.Contains(err.Error(), "--allow-empty") {
+		t.Errorf("Expected empty content error, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("allow-empty", "true")
+	if err := Upload(cmd, []string{tmpfile.Name()}); err != nil {
+		t.Errorf("Expected --allow-empty to permit upload, got %v", err)
+	}
+}
+
+func TestUploadHandlerDryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	tmpfile, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte("test content")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("dry-run", "true")
+	_ = cmd.Flags().Set("expires", "7d")
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := Upload(cmd, []string{tmpfile.Name()}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, filepath.Base(tmpfile.Name())) || !strings.Contains(output, "168h") {
+		t.Errorf("Expected resolved filename and expiry in output, got %s", output)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests in dry-run mode, got %d", requests)
+	}
+
+	_ = cmd.Flags().Set("expires", "soon")
+	if err := Upload(cmd, []string{tmpfile.Name()}); err == nil {
+		t.Error("Expected dry-run to still validate --expires")
+	}
+}
+
+func TestDeleteHandlerUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "bad-key")
+	client.Initialize()
+
+	cmd := NewDeleteCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("yes", "true")
+
+	err := Delete(cmd, []string{"abc123"})
+	if err == nil || !strings.Contains(err.Error(), "API key invalid") {
+		t.Errorf("Expected API key invalid error, got %v", err)
+	}
+}
+
+func TestUploadHandlerDirectory(t *testing.T) {
+	var received int
+	var filename, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = len(body)
+		filename = r.Header.Get("X-Filename")
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	dir := filepath.Join(t.TempDir(), "mydir")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("test content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := Upload(cmd, []string{dir}); err == nil || !strings.Contains(err.Error(), "--archive") {
+		t.Errorf("Expected directory error mentioning --archive, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("archive", "rar")
+	if err := Upload(cmd, []string{dir}); err == nil || !strings.Contains(err.Error(), "invalid archive format") {
+		t.Errorf("Expected invalid archive format error, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("archive", "zip")
+	if err := Upload(cmd, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	if received == 0 || filename != "mydir.zip" || contentType != "application/zip" {
+		t.Errorf("Unexpected archive upload: %d bytes, filename %q, content type %q", received, filename, contentType)
+	}
+	if !strings.Contains(buf.String(), "Archive size:") {
+		t.Errorf("Expected archive size in output, got %s", buf.String())
+	}
+}
+
+func TestUploadHandlerStdin(t *testing.T) {
+	var filename, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		filename = r.Header.Get("X-Filename")
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	text := strings.Repeat("héllo wörld ", 100)
+	png := append([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, make([]byte, 1024)...)
+
+	tests := []struct {
+		name        string
+		input       []byte
+		args        []string
+		stdinName   string
+		defaultName string
+		wantName    string
+		wantType    string
+		wantErrText string
+	}{
+		{name: "text", input: []byte(text), wantName: "paste.txt", wantType: "application/octet-stream"},
+		{name: "dash", input: []byte(text), args: []string{"-"}, wantName: "paste.txt", wantType: "application/octet-stream"},
+		{name: "png", input: png, wantName: "paste.png", wantType: "image/png"},
+		{name: "binary", input: []byte{0x00, 0xff, 0xfe, 0x01}, wantName: "paste.bin", wantType: "application/octet-stream"},
+		{name: "stdin-name", input: []byte(text), stdinName: "notes.md", wantName: "notes.md", wantType: "application/octet-stream"},
+		{name: "configured default", input: []byte(text), defaultName: "paste.json", wantName: "paste.json", wantType: "application/octet-stream"},
+		{name: "configured default png", input: png, defaultName: "paste.json", wantName: "paste.png", wantType: "image/png"},
+		{name: "stdin-name over default", input: []byte(text), stdinName: "notes.md", defaultName: "paste.json", wantName: "notes.md", wantType: "application/octet-stream"},
+		{name: "empty", input: nil, wantErrText: "--allow-empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename, contentType, body = "", "", ""
+
+			cmd := NewUploadCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetIn(bytes.NewReader(tt.input))
+			if tt.stdinName != "" {
+				_ = cmd.Flags().Set("stdin-name", tt.stdinName)
+			}
+			viper.Set("default_stdin_filename", tt.defaultName)
+			defer viper.Set("default_stdin_filename", "")
+
+			err := Upload(cmd, tt.args)
+			if tt.wantErrText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErrText, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filename != tt.wantName || contentType != tt.wantType {
+				t.Errorf("Expected %s (%s), got %s (%s)", tt.wantName, tt.wantType, filename, contentType)
+			}
+			if body != string(tt.input) {
+				t.Errorf("Expected the full input to be uploaded, got %d of %d bytes", len(body), len(tt.input))
+			}
+		})
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("stdin-name", "notes.md")
+	if err := Upload(cmd, []string{"handlers.go"}); err == nil {
+		t.Error("Expected --stdin-name to be rejected for file uploads")
+	}
+}
+
+func TestOpenHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abc123", "/abc123/raw":
+			w.WriteHeader(http.StatusOK)
+		case "/short1":
+			http.Redirect(w, r, "https://example.com", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	var opened string
+	oldOpen := openBrowser
+	openBrowser = func(target string) error {
+		opened = target
+		return nil
+	}
+	defer func() { openBrowser = oldOpen }()
+
+	cmd := NewOpenCmd()
+	cmd.SetOut(&bytes.Buffer{})
+
+	if err := Open(cmd, []string{"short1"}); err != nil {
+		t.Fatal(err)
+	}
+	if opened != server.URL+"/short1" {
+		t.Errorf("Expected short URL to be opened, got %s", opened)
+	}
+
+	_ = cmd.Flags().Set("raw", "true")
+	if err := Open(cmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if opened != server.URL+"/abc123/raw" {
+		t.Errorf("Expected raw URL to be opened, got %s", opened)
+	}
+
+	opened = ""
+	if err := Open(cmd, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if opened != "" {
+		t.Errorf("Expected nothing to be opened for a missing ID, got %s", opened)
+	}
+}
+
+func TestDeleteHandlerConfirm(t *testing.T) {
+	deletes := 0
+	server := setupTestServer()
+	defer server.Close()
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes++
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer counting.Close()
+
+	viper.Set("api_url", counting.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	oldCanPrompt := canPrompt
+	defer func() { canPrompt = oldCanPrompt }()
+
+	// Without a terminal to prompt on, --yes is required.
+	canPrompt = func(*cobra.Command) bool { return false }
+	cmd := NewDeleteCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	if err := Delete(cmd, []string{"abc123"}); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("Expected --yes to be required, got %v", err)
+	}
+
+	canPrompt = func(*cobra.Command) bool { return true }
+	for _, tt := range []struct {
+		answer      string
+		wantDeletes int
+	}{
+		{answer: "\n", wantDeletes: 0},
+		{answer: "n\n", wantDeletes: 0},
+		{answer: "y\n", wantDeletes: 1},
+	} {
+		deletes = 0
+		var stderr bytes.Buffer
+		cmd := NewDeleteCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&stderr)
+		cmd.SetIn(strings.NewReader(tt.answer))
+
+		if err := Delete(cmd, []string{"abc123"}); err != nil {
+			t.Fatal(err)
+		}
+		if deletes != tt.wantDeletes {
+			t.Errorf("Answer %q: expected %d deletes, got %d", tt.answer, tt.wantDeletes, deletes)
+		}
+		if !strings.Contains(stderr.String(), "test.txt") {
+			t.Errorf("Expected prompt to describe the paste, got %q", stderr.String())
+		}
+	}
+}
+
+func TestDeleteHandlerMultiple(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewDeleteCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("yes", "true")
+
+	err := Delete(cmd, []string{"abc123", "missing", "abc123"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 deletions failed") {
+		t.Errorf("Expected one failed deletion, got %v", err)
+	}
+
+	output := buf.String()
+	if strings.Count(output, "Deleted successfully") != 2 {
+		t.Errorf("Expected the other deletions to go ahead, got %s", output)
+	}
+	if !strings.Contains(output, "missing: error deleting content: not found") {
+		t.Errorf("Expected per-ID failure in output, got %s", output)
+	}
+}
+
+func TestDeleteHandlerByURL(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/pastes":
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{
+				{Id: "abc123", URL: "https://0x45.st/abc123", DeleteURL: "https://0x45.st/delete/del456"},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/urls":
+			resp := api.ListResponse[api.URLListItem]{Success: true}
+			resp.Data.Items = []api.URLListItem{
+				{Id: "u1", ShortURL: "https://0x45.st/u/xyz"},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case strings.HasPrefix(r.URL.Path, "/delete/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/delete/"))
+			_ = json.NewEncoder(w).Encode(api.GenericResponse{Success: true, Message: "Deleted successfully"})
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewDeleteCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("yes", "true")
+	_ = cmd.Flags().Set("by-url", "true")
+	if err := Delete(cmd, []string{"https://0x45.st/abc123#key=secret", "https://0x45.st/u/xyz"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(deleted, ",") != "del456,u1" {
+		t.Errorf("Expected the IDs from the delete URL and the URL list, got %v", deleted)
+	}
+
+	deleted = nil
+	var notFound *notFoundError
+	if err := Delete(cmd, []string{"https://0x45.st/someone-elses"}); !errors.As(err, &notFound) || len(deleted) != 0 {
+		t.Errorf("Expected a not found error and nothing deleted, got %v and %v", err, deleted)
+	}
+
+	var usageErr *UsageError
+	if err := Delete(cmd, []string{"abc123"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for an ID with --by-url, got %v", err)
+	}
+}
+
+func TestValidateShortenURL(t *testing.T) {
+	tests := []struct {
+		raw       string
+		anyScheme bool
+		wantErr   bool
+	}{
+		{raw: "https://example.com"},
+		{raw: "http://example.com/path?q=1"},
+		{raw: "htps://example.com", wantErr: true},
+		{raw: "example.com", wantErr: true},
+		{raw: "https://", wantErr: true},
+		{raw: "ftp://example.com/file", wantErr: true},
+		{raw: "ftp://example.com/file", anyScheme: true},
+		{raw: "mailto:someone@example.com", anyScheme: true},
+		{raw: "example.com", anyScheme: true, wantErr: true},
+		{raw: "http://[::1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := validateShortenURL(tt.raw, tt.anyScheme)
+		if tt.wantErr && err == nil {
+			t.Errorf("validateShortenURL(%q, %v): expected error", tt.raw, tt.anyScheme)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("validateShortenURL(%q, %v): unexpected error: %v", tt.raw, tt.anyScheme, err)
+		}
+	}
+}
+
+func TestListAllHandler(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewListCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := List(cmd, []string{"all"}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "paste") || !strings.Contains(output, "test.txt") {
+		t.Errorf("Expected paste entry in output, got %s", output)
+	}
+	if !strings.Contains(output, "url") || !strings.Contains(output, "https://example.com") {
+		t.Errorf("Expected URL entry in output, got %s", output)
+	}
+
+	_ = cmd.Flags().Set("per-page", "1")
+	buf.Reset()
+	if err := List(cmd, []string{"all"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "ID") != 1 {
+		t.Errorf("Expected --per-page to limit the combined list, got %s", buf.String())
+	}
+}
+
+func TestMergeEntries(t *testing.T) {
+	pastes := []api.PasteListItem{
+		{Id: "p1", CreatedAt: "2024-01-01T00:00:00Z"},
+		{Id: "p2", CreatedAt: "2024-01-03T00:00:00Z"},
+	}
+	urls := []api.URLListItem{
+		{Id: "u1", CreatedAt: "2024-01-02T00:00:00Z"},
+		{Id: "u2", CreatedAt: "not a time"},
+	}
+
+	ids := func(entries []listEntry) string {
+		var out []string
+		for _, entry := range entries {
+			if entry.Paste != nil {
+				out = append(out, entry.Paste.Id)
+			} else {
+				out = append(out, entry.URL.Id)
+			}
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := ids(mergeEntries(pastes, urls, false)); got != "p2,u1,p1,u2" {
+		t.Errorf("Expected newest first, got %s", got)
+	}
+	if got := ids(mergeEntries(pastes, urls, true)); got != "p1,u1,p2,u2" {
+		t.Errorf("Expected oldest first, got %s", got)
+	}
+}
+
+func TestUploadHandlerContent(t *testing.T) {
+	var filename, body string
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		filename = r.Header.Get("X-Filename")
+		contentLength = r.ContentLength
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("stdin should be ignored"))
+	_ = cmd.Flags().Set("content", "hello world")
+
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if body != "hello world" || filename != "paste.txt" || contentLength != int64(len("hello world")) {
+		t.Errorf("Unexpected upload: body %q, filename %q, length %d", body, filename, contentLength)
+	}
+
+	if err := Upload(cmd, []string{"-"}); err == nil || !strings.Contains(err.Error(), "--content cannot be combined") {
+		t.Errorf("Expected conflicting input error, got %v", err)
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("content", "")
+	if err := Upload(cmd, nil); !errors.Is(err, errEmptyUpload) {
+		t.Errorf("Expected empty content to be refused, got %v", err)
+	}
+}
+
+func TestUploadHandlerLang(t *testing.T) {
+	var filename, ext string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filename = r.Header.Get("X-Filename")
+		ext = r.URL.Query().Get("ext")
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	tests := []struct {
+		lang     string
+		ext      string
+		filename string
+	}{
+		{"go", "go", "paste.go"},
+		{"Python", "py", "paste.py"},
+		{".nim", "nim", "paste.nim"},
+	}
+	for _, tt := range tests {
+		cmd := NewUploadCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader("package main\n"))
+		_ = cmd.Flags().Set("lang", tt.lang)
+
+		if err := Upload(cmd, []string{"-"}); err != nil {
+			t.Fatal(err)
+		}
+		if ext != tt.ext || filename != tt.filename {
+			t.Errorf("--lang %s: got ext %q, filename %q; want %q, %q", tt.lang, ext, filename, tt.ext, tt.filename)
+		}
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("lang", "../etc")
+	_ = cmd.Flags().Set("content", "x")
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected usage error for invalid language, got %v", err)
+	}
+}
+
+func TestUploadHandlerTitle(t *testing.T) {
+	var title string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pastes":
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{{Id: "abc123", Filename: "notes.txt", Title: "Meeting notes"}}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			title = r.URL.Query().Get("title")
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("content", "hello")
+	_ = cmd.Flags().Set("title", " Meeting notes ")
+
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if title != "Meeting notes" {
+		t.Errorf("Expected the trimmed title to be sent, got %q", title)
+	}
+	if !strings.Contains(buf.String(), "Title: Meeting notes") {
+		t.Errorf("Expected the title in the output, got %s", buf.String())
+	}
+
+	listCmd := NewListCmd()
+	buf.Reset()
+	listCmd.SetOut(&buf)
+	if err := List(listCmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Meeting notes") {
+		t.Errorf("Expected the title in the list, got %s", buf.String())
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("content", "hello")
+	_ = cmd.Flags().Set("title", "two\nlines")
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected usage error for a multi-line title, got %v", err)
+	}
+}
+
+func TestUploadHandlerIfNewer(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pastes":
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{{Id: "abc123", URL: "https://0x45.st/abc123", CreatedAt: created.Format(time.RFC3339)}}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/upload":
+			uploads++
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/new456"})
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	path := filepath.Join(t.TempDir(), "backup.txt")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		modTime time.Time
+		ref     string
+		want    string
+		uploads int
+	}{
+		{"older", created.Add(-time.Hour), "abc123", "https://0x45.st/abc123", 0},
+		{"newer", created.Add(time.Hour), "https://0x45.st/abc123", "https://0x45.st/new456", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads = 0
+			if err := os.Chtimes(path, tt.modTime, tt.modTime); err != nil {
+				t.Fatal(err)
+			}
+			cmd := NewUploadCmd()
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&bytes.Buffer{})
+			_ = cmd.Flags().Set("if-newer", tt.ref)
+			if err := Upload(cmd, []string{path}); err != nil {
+				t.Fatal(err)
+			}
+			if uploads != tt.uploads || !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Expected %d uploads and %s, got %d and %s", tt.uploads, tt.want, uploads, buf.String())
+			}
+		})
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("if-newer", "missing")
+	if err := Upload(cmd, []string{path}); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("Expected a not found error for an unknown paste, got %v", err)
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("if-newer", "abc123")
+	_ = cmd.Flags().Set("content", "x")
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error with --content, got %v", err)
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	expires := "2024-01-01T00:00:00Z"
+	resp := api.ListResponse[api.PasteListItem]{Success: true}
+	resp.Data.Items = []api.PasteListItem{{Id: "abc123", Size: 42, ExpiresAt: &expires}, {Id: "def456"}}
+
+	tests := []struct {
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{path: "success", want: true},
+		{path: "data.items.0.id", want: "abc123"},
+		{path: "data.items.0.size", want: int64(42)},
+		{path: "data.items.0.expires_at", want: "2024-01-01T00:00:00Z"},
+		{path: "data.items.1.expires_at", want: nil},
+		{path: "data.items.2.id", wantErr: true},
+		{path: "data.url", wantErr: true},
+		{path: "success.value", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := lookupField(&resp, tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("lookupField(%q): expected error, got %v", tt.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("lookupField(%q): unexpected error: %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("lookupField(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFieldOutput(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewShortenCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("field", "delete_url")
+	if err := Shorten(cmd, []string{"https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "https://0x45.st/delete/abc123\n" {
+		t.Errorf("Expected only the delete URL, got %q", buf.String())
+	}
+
+	cmd = NewUploadCmd()
+	buf.Reset()
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("content", "hello")
+	_ = cmd.Flags().Set("field", "url")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "https://0x45.st/abc123\n" {
+		t.Errorf("Expected only the URL, got %q", buf.String())
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("content", "hello")
+	_ = cmd.Flags().Set("field", "data.url")
+	err := Upload(cmd, nil)
+	var usageErr *UsageError
+	if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "available: success, url") {
+		t.Errorf("Expected a usage error listing the available fields, got %v", err)
+	}
+}
+
+func TestQuietOutput(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	viper.Set("quiet", true)
+	defer viper.Set("quiet", false)
+	client.Initialize()
+
+	upload := NewUploadCmd()
+	var buf bytes.Buffer
+	upload.SetOut(&buf)
+	_ = upload.Flags().Set("content", "hello")
+	_ = upload.Flags().Set("title", "Greeting")
+	if err := Upload(upload, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "https://0x45.st/abc123\n" {
+		t.Errorf("Expected only the upload URL, got %q", buf.String())
+	}
+
+	buf.Reset()
+	shorten := NewShortenCmd()
+	shorten.SetOut(&buf)
+	if err := Shorten(shorten, []string{"https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "https://0x45.st/abc123\n" {
+		t.Errorf("Expected only the short URL, got %q", buf.String())
+	}
+
+	buf.Reset()
+	var stderr bytes.Buffer
+	del := NewDeleteCmd()
+	del.SetOut(&buf)
+	del.SetErr(&stderr)
+	_ = del.Flags().Set("yes", "true")
+	if err := Delete(del, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(del, []string{"abc123", "missing"}); err == nil {
+		t.Error("Expected the missing deletion to fail")
+	}
+	if buf.String() != "" {
+		t.Errorf("Expected no output on success, got %q", buf.String())
+	}
+	if !strings.Contains(stderr.String(), "missing: error deleting content") {
+		t.Errorf("Expected the failure on stderr, got %q", stderr.String())
+	}
+}
+
+func TestFormatOutput(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("content", "hello")
+	_ = cmd.Flags().Set("format", "{{.url}} ({{.mime_type}}){{if .title}} {{.title}}{{end}}")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "https://0x45.st/abc123 (text/plain)\n" {
+		t.Errorf("Expected the rendered template, got %q", buf.String())
+	}
+
+	cmd = NewListCmd()
+	buf.Reset()
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("format", "{{.id}}\t{{.size}}\n")
+	if err := List(cmd, []string{"pastes"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "abc123\t123\n" {
+		t.Errorf("Expected one line per paste, got %q", buf.String())
+	}
+
+	// A broken template is caught before anything is sent.
+	requests := 0
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer counting.Close()
+	viper.Set("api_url", counting.URL)
+	client.Initialize()
+
+	cmd = NewShortenCmd()
+	_ = cmd.Flags().Set("format", "{{.url")
+	var usageErr *UsageError
+	if err := Shorten(cmd, []string{"https://example.com"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for an invalid template, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests, got %d", requests)
+	}
+}
+
+func TestListHandlerJSONLines(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	for _, listType := range []string{"pastes", "urls", "all"} {
+		cmd := NewListCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		_ = cmd.Flags().Set("jsonl", "true")
+		if err := List(cmd, []string{listType}); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		for _, line := range lines {
+			var item map[string]any
+			if err := json.Unmarshal([]byte(line), &item); err != nil || strings.Contains(line, "\x1b") {
+				t.Errorf("%s: expected a plain JSON object per line, got %q: %v", listType, line, err)
+			}
+		}
+		if len(lines) == 0 || lines[0] == "" {
+			t.Errorf("%s: expected at least one line", listType)
+		}
+	}
+
+	cmd := NewListCmd()
+	_ = cmd.Flags().Set("jsonl", "true")
+	_ = cmd.Flags().Set("table", "true")
+	var usageErr *UsageError
+	if err := List(cmd, []string{"pastes"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error with --table, got %v", err)
+	}
+}
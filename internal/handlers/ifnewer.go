@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// findPaste looks up one of your pastes by ID, paging through the list
+// until it turns up, since the API has no endpoint for a single paste's
+// metadata.
+func findPaste(id string) (*api.PasteListItem, error) {
+	opts := api.ListOptions{PerPage: maxPerPage}
+	for opts.Page = 1; opts.Page <= maxPages; opts.Page++ {
+		resp, err := client.ListPastes(opts)
+		if err != nil {
+			return nil, wrapAPIError("error looking up paste", err)
+		}
+		if !resp.Success {
+			return nil, fmt.Errorf("error looking up paste: %s", resp.Error)
+		}
+		for _, item := range resp.Data.Items {
+			if item.Id == id {
+				return &item, nil
+			}
+		}
+		if len(resp.Data.Items) < opts.PerPage {
+			break
+		}
+	}
+	return nil, notFoundErrorf("paste not found among your pastes: %s", id)
+}
+
+// newerThanPaste reports whether a file modified at modTime is newer than
+// the paste was when it was uploaded.
+func newerThanPaste(modTime time.Time, paste *api.PasteListItem) (bool, error) {
+	created, err := time.Parse(time.RFC3339, paste.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("can't tell when paste %s was uploaded: invalid created_at %q", paste.Id, paste.CreatedAt)
+	}
+	return modTime.After(created), nil
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func NewKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "key",
+		Short: "Manage the API keys of your account",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the API keys of your account",
+		Long: `List the API keys tied to the account of the configured API key.
+
+Key values are never shown in full, only their last four characters.`,
+		Args: cobra.NoArgs,
+		RunE: KeyList,
+	}
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke [id]",
+		Short: "Revoke one of your API keys",
+		Long: `Revoke an API key by the ID shown by 'key list'. Anything still using the
+key stops working, including this CLI if it is the configured key.`,
+		Example: "  0x45 key revoke k_123\n  0x45 key revoke k_123 --yes",
+		Args:    cobra.ExactArgs(1),
+		RunE:    KeyRevoke,
+	}
+	revokeCmd.Flags().BoolP("yes", "y", false, "Revoke without asking for confirmation")
+
+	cmd.AddCommand(listCmd, revokeCmd)
+	return cmd
+}
+
+// listKeys fetches the account's API keys with their values redacted, so
+// no caller can print one by mistake.
+func listKeys() ([]api.KeyListItem, error) {
+	resp, err := client.ListKeys()
+	if err != nil {
+		return nil, wrapAPIError("error listing API keys", err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("error listing API keys: %s", resp.Error)
+	}
+
+	keys := resp.Data.Items
+	for i := range keys {
+		keys[i].Key = redactSecret(keys[i].Key)
+	}
+	return keys, nil
+}
+
+func KeyList(cmd *cobra.Command, args []string) error {
+	keys, err := listKeys()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if keys == nil {
+			keys = []api.KeyListItem{}
+		}
+		return printJSON(cmd, keys)
+	}
+
+	out := cmd.OutOrStdout()
+	if quietOutput() {
+		for _, key := range keys {
+			fmt.Fprintln(out, key.Id)
+		}
+		return nil
+	}
+
+	fmt.Fprintln(out, theme.Title.Render("Your API Keys"))
+	if len(keys) == 0 {
+		fmt.Fprintln(out, "No API keys found")
+		return nil
+	}
+	for _, key := range keys {
+		printKeyItem(out, key)
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func printKeyItem(w io.Writer, key api.KeyListItem) {
+	fmt.Fprintln(w, theme.FormatKeyValue("ID", key.Id))
+	if key.Name != "" {
+		fmt.Fprintln(w, theme.FormatKeyValue("Name", key.Name))
+	}
+	if key.Key != "" {
+		fmt.Fprintln(w, theme.FormatKeyValue("Key", key.Key))
+	}
+	fmt.Fprintln(w, theme.FormatKeyValue("Created", formatTimestamp(key.CreatedAt)))
+	lastUsed := "never"
+	if key.LastUsedAt != nil {
+		lastUsed = formatTimestamp(*key.LastUsedAt)
+	}
+	fmt.Fprintln(w, theme.FormatKeyValue("Last used", lastUsed))
+	if key.ExpiresAt != nil {
+		fmt.Fprintln(w, theme.FormatKeyValue("Expires", formatTimestamp(*key.ExpiresAt)))
+	}
+}
+
+func KeyRevoke(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+	id := args[0]
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+	if !yes && !canPrompt(cmd) {
+		return usageErrorf("refusing to revoke without confirmation: pass --yes when not running interactively")
+	}
+
+	if !yes {
+		// The key is described if it can be found; the server has the final
+		// say on whether the ID exists.
+		desc := id
+		if keys, err := listKeys(); err == nil {
+			for _, key := range keys {
+				switch {
+				case key.Id != id:
+				case key.Name != "":
+					desc = fmt.Sprintf("%s (%s, %s)", id, key.Name, key.Key)
+				default:
+					desc = fmt.Sprintf("%s (%s)", id, key.Key)
+				}
+			}
+		}
+		fmt.Fprintln(cmd.ErrOrStderr(), "About to revoke API key", desc)
+		fmt.Fprintln(cmd.ErrOrStderr(), "Anything using it will stop working.")
+		if !confirm(cmd, "Are you sure?") {
+			fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatWarning("Aborted"))
+			return nil
+		}
+	}
+
+	logging.Infof("Revoking API key %s", id)
+	resp, err := client.RevokeKey(id)
+	if errors.Is(err, api.ErrNotFound) {
+		return notFoundErrorf("API key not found: %s", id)
+	}
+	if err != nil {
+		return wrapAPIError("error revoking API key", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("error revoking API key: %s", resp.Error)
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, resp)
+	}
+	if !quietOutput() {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("API key %s revoked", id)))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestKeyHandlers(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/keys":
+			resp := api.ListResponse[api.KeyListItem]{Success: true}
+			resp.Data.Items = []api.KeyListItem{
+				{Id: "k1", Name: "laptop", Key: "sk_live_abcdef123456", CreatedAt: "2024-01-01T00:00:00Z"},
+				{Id: "k2", Key: "sk_live_zyxwvu987654", CreatedAt: "2024-02-01T00:00:00Z"},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == http.MethodDelete && r.URL.Path == "/keys/k1":
+			revoked = append(revoked, "k1")
+			_ = json.NewEncoder(w).Encode(api.GenericResponse{Success: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+	if err := KeyList(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "laptop") || !strings.Contains(output, "3456") || !strings.Contains(output, "7654") {
+		t.Errorf("Expected both keys in the list, got:\n%s", output)
+	}
+	if strings.Contains(output, "sk_live") {
+		t.Errorf("Expected key values to be redacted, got:\n%s", output)
+	}
+
+	viper.Set("json", true)
+	buf.Reset()
+	err := KeyList(cmd, nil)
+	viper.Set("json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "sk_live") {
+		t.Errorf("Expected key values to be redacted in JSON too, got %s", buf.String())
+	}
+
+	oldCanPrompt := canPrompt
+	defer func() { canPrompt = oldCanPrompt }()
+
+	revoke := func(input, id string, flags ...string) (string, error) {
+		cmd := NewKeyCmd()
+		revokeCmd, _, _ := cmd.Find([]string{"revoke"})
+		var buf bytes.Buffer
+		revokeCmd.SetOut(&buf)
+		revokeCmd.SetErr(&buf)
+		revokeCmd.SetIn(strings.NewReader(input))
+		for i := 0; i < len(flags); i += 2 {
+			_ = revokeCmd.Flags().Set(flags[i], flags[i+1])
+		}
+		err := KeyRevoke(revokeCmd, []string{id})
+		return buf.String(), err
+	}
+
+	canPrompt = func(*cobra.Command) bool { return false }
+	var usageErr *UsageError
+	if _, err := revoke("", "k1"); !errors.As(err, &usageErr) || len(revoked) != 0 {
+		t.Errorf("Expected revoking without a prompt or --yes to be refused, got %v", err)
+	}
+
+	canPrompt = func(*cobra.Command) bool { return true }
+	output, err = revoke("n\n", "k1")
+	if err != nil || len(revoked) != 0 {
+		t.Errorf("Expected answering no to keep the key, got %v and %v", err, revoked)
+	}
+	if !strings.Contains(output, "k1 (laptop, ") || strings.Contains(output, "sk_live") {
+		t.Errorf("Expected the redacted key to be described, got:\n%s", output)
+	}
+
+	if _, err := revoke("y\n", "k1"); err != nil || len(revoked) != 1 {
+		t.Errorf("Expected answering yes to revoke the key, got %v and %v", err, revoked)
+	}
+
+	if _, err := revoke("", "missing", "yes", "true"); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("Expected an unknown key to be not found, got %v", err)
+	}
+}
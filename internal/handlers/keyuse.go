@@ -0,0 +1,37 @@
+package handlers
+
+import "github.com/spf13/cobra"
+
+// apiKeyAnnotation records on a command how it uses the API key. Its
+// subcommands inherit it.
+const apiKeyAnnotation = "0x45_api_key"
+
+// Values of apiKeyAnnotation. Commands without one need an API key.
+const (
+	// apiKeyOptional marks commands that also work anonymously, with
+	// whatever limits the server puts on anonymous use.
+	apiKeyOptional = "optional"
+	// apiKeyUnused marks commands that never talk to the server.
+	apiKeyUnused = "unused"
+)
+
+// apiKeyUse returns the apiKeyAnnotation cmd has or inherits, or "".
+func apiKeyUse(cmd *cobra.Command) string {
+	for c := cmd; c != nil; c = c.Parent() {
+		if use := c.Annotations[apiKeyAnnotation]; use != "" {
+			return use
+		}
+	}
+	return ""
+}
+
+// UsesServer reports whether cmd talks to the server, and so needs the
+// client set up and the API key checked before it runs.
+func UsesServer(cmd *cobra.Command) bool {
+	return apiKeyUse(cmd) != apiKeyUnused
+}
+
+// RequiresAPIKey reports whether cmd refuses to run without an API key.
+func RequiresAPIKey(cmd *cobra.Command) bool {
+	return apiKeyUse(cmd) == ""
+}
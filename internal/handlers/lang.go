@@ -0,0 +1,50 @@
+package handlers
+
+import "strings"
+
+// langExtensions maps language names accepted by --lang to the file
+// extension the server uses to pick a syntax highlighter.
+var langExtensions = map[string]string{
+	"bash":       "sh",
+	"c":          "c",
+	"c#":         "cs",
+	"c++":        "cpp",
+	"cpp":        "cpp",
+	"csharp":     "cs",
+	"css":        "css",
+	"elixir":     "ex",
+	"go":         "go",
+	"golang":     "go",
+	"haskell":    "hs",
+	"html":       "html",
+	"java":       "java",
+	"javascript": "js",
+	"json":       "json",
+	"kotlin":     "kt",
+	"lua":        "lua",
+	"markdown":   "md",
+	"perl":       "pl",
+	"php":        "php",
+	"python":     "py",
+	"ruby":       "rb",
+	"rust":       "rs",
+	"shell":      "sh",
+	"sql":        "sql",
+	"swift":      "swift",
+	"text":       "txt",
+	"toml":       "toml",
+	"typescript": "ts",
+	"xml":        "xml",
+	"yaml":       "yaml",
+	"zig":        "zig",
+}
+
+// langExtension returns the extension for a language name, falling back to
+// the value itself (without a leading dot) for names not in the map.
+func langExtension(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if ext, ok := langExtensions[lang]; ok {
+		return ext
+	}
+	return strings.TrimPrefix(lang, ".")
+}
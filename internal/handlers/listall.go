@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// Entry types in a combined listing.
+const (
+	entryPaste = "paste"
+	entryURL   = "url"
+)
+
+// listEntry is one paste or shortened URL in a combined listing.
+type listEntry struct {
+	Type  string             `json:"type"`
+	Paste *api.PasteListItem `json:"paste,omitempty"`
+	URL   *api.URLListItem   `json:"url,omitempty"`
+
+	createdAt time.Time
+}
+
+// listAll merges pastes and URLs into one list ordered by creation time.
+// Unless every page is fetched, the combined list is cut to the page size.
+func listAll(cmd *cobra.Command, opts api.ListOptions, all bool, filter string, dates dateRange, table bool, pasteURLs pasteURLOptions) error {
+	var pastes *api.ListResponse[api.PasteListItem]
+	var urls *api.ListResponse[api.URLListItem]
+	var err error
+	if all {
+		pastes, err = fetchAll(client.ListPastes, opts)
+	} else {
+		pastes, err = client.ListPastes(opts)
+	}
+	if err != nil {
+		return wrapAPIError("error listing pastes", err)
+	}
+	if !pastes.Success {
+		return fmt.Errorf("error listing pastes: %s", pastes.Error)
+	}
+
+	if all {
+		urls, err = fetchAll(client.ListURLs, opts)
+	} else {
+		urls, err = client.ListURLs(opts)
+	}
+	if err != nil {
+		return wrapAPIError("error listing URLs", err)
+	}
+	if !urls.Success {
+		return fmt.Errorf("error listing URLs: %s", urls.Error)
+	}
+
+	pastes.Data.Items = filterByDate(pastes.Data.Items, dates, func(item api.PasteListItem) string {
+		return item.CreatedAt
+	})
+	urls.Data.Items = filterByDate(urls.Data.Items, dates, func(item api.URLListItem) string {
+		return item.CreatedAt
+	})
+
+	fetched := len(pastes.Data.Items) + len(urls.Data.Items)
+	if filter != "" {
+		pastes.Data.Items = filterItems(pastes.Data.Items, filter, func(item api.PasteListItem) []string {
+			return []string{item.Filename, item.URL}
+		})
+		urls.Data.Items = filterItems(urls.Data.Items, filter, func(item api.URLListItem) []string {
+			return []string{item.URL, item.ShortURL, item.OriginalURL}
+		})
+	}
+
+	entries := mergeEntries(pastes.Data.Items, urls.Data.Items, opts.Order == "asc")
+	if !all && opts.PerPage > 0 && len(entries) > opts.PerPage {
+		entries = entries[:opts.PerPage]
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, entries)
+	}
+	if jsonl, _ := cmd.Flags().GetBool("jsonl"); jsonl {
+		return printJSONLines(cmd, entries)
+	}
+	if tmpl, _ := formatFlag(cmd); tmpl != nil {
+		return printTemplateEach(cmd, tmpl, entries)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, theme.Title.Render("Your Pastes and URLs"))
+	if filter != "" {
+		fmt.Fprintln(out, theme.Subtitle.Render(fmt.Sprintf("%d of %d items match %q", len(entries), fetched, filter)))
+	}
+
+	if table {
+		renderEntryTable(out, entries)
+		return nil
+	}
+
+	printEntries(out, entries, pasteURLs)
+	return nil
+}
+
+// printEntries prints each entry of a combined listing, marked with its type.
+func printEntries(w io.Writer, entries []listEntry, pasteURLs pasteURLOptions) {
+	for _, entry := range entries {
+		fmt.Fprintln(w, theme.FormatKeyValue("Type", entry.Type))
+		if entry.Paste != nil {
+			printPasteItem(w, *entry.Paste, pasteURLs)
+		} else {
+			printURLItem(w, *entry.URL)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// mergeEntries combines pastes and URLs sorted by creation time, newest
+// first unless ascending is set. Entries with unparseable times sort last.
+func mergeEntries(pastes []api.PasteListItem, urls []api.URLListItem, ascending bool) []listEntry {
+	entries := make([]listEntry, 0, len(pastes)+len(urls))
+	for i := range pastes {
+		createdAt, _ := time.Parse(time.RFC3339, pastes[i].CreatedAt)
+		entries = append(entries, listEntry{Type: entryPaste, Paste: &pastes[i], createdAt: createdAt})
+	}
+	for i := range urls {
+		createdAt, _ := time.Parse(time.RFC3339, urls[i].CreatedAt)
+		entries = append(entries, listEntry{Type: entryURL, URL: &urls[i], createdAt: createdAt})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].createdAt, entries[j].createdAt
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		if ascending {
+			return a.Before(b)
+		}
+		return a.After(b)
+	})
+	return entries
+}
+
+func renderEntryTable(w io.Writer, entries []listEntry) {
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Paste != nil {
+			rows = append(rows, []string{
+				entry.Type,
+				entry.Paste.Id,
+				entry.Paste.Filename,
+				humanize.Bytes(uint64(entry.Paste.Size)),
+				formatTableTime(&entry.Paste.CreatedAt),
+			})
+			continue
+		}
+		rows = append(rows, []string{
+			entry.Type,
+			entry.URL.Id,
+			entry.URL.OriginalURL,
+			fmt.Sprintf("%d clicks", entry.URL.Clicks),
+			formatTableTime(&entry.URL.CreatedAt),
+		})
+	}
+	renderTable(w, []string{"Type", "ID", "Name", "Details", "Created"}, rows, terminalWidth(), 2)
+}
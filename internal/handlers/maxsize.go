@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maxSizeFlag returns the largest upload allowed, in bytes: --max-size if
+// given, then the max_upload_size config value, then fallback. 0 means no
+// limit.
+func maxSizeFlag(cmd *cobra.Command, fallback int64) (int64, error) {
+	value, err := cmd.Flags().GetString("max-size")
+	if err != nil {
+		return 0, err
+	}
+	if value != "" {
+		size, err := parseSize(value)
+		if err != nil {
+			return 0, usageErrorf("invalid size %q: use a number of bytes, optionally with a unit (e.g. 10MB)", value)
+		}
+		return size, nil
+	}
+
+	if value := viper.GetString("max_upload_size"); value != "" {
+		size, err := parseSize(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max_upload_size %q in config: use a number of bytes, optionally with a unit (e.g. 10MB)", value)
+		}
+		return size, nil
+	}
+	return fallback, nil
+}
+
+// parseSize parses a human-friendly size such as "10MB" or "1.5GiB".
+func parseSize(value string) (int64, error) {
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, err
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("size must be more than 0")
+	}
+	return int64(size), nil
+}
+
+// checkSize rejects content of a known size over limit, before anything is
+// uploaded. A limit of 0 allows any size.
+func checkSize(limit, size int64, what string) error {
+	if limit > 0 && size > limit {
+		return sizeLimitError(limit, fmt.Sprintf("%s (%s)", what, humanize.Bytes(uint64(size))))
+	}
+	return nil
+}
+
+// sizeLimitError reports content over the --max-size limit.
+func sizeLimitError(limit int64, what string) error {
+	return usageErrorf("%s is larger than the maximum of %s (raise it with --max-size)", what, humanize.Bytes(uint64(limit)))
+}
+
+// limitReader fails reads once more than limit bytes have been read, so an
+// upload of unknown size is aborted rather than sent in full.
+type limitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+// limitSize wraps r in a limitReader, or returns nil for a limit of 0.
+func limitSize(r io.Reader, limit int64) *limitReader {
+	if limit <= 0 {
+		return nil
+	}
+	return &limitReader{r: r, limit: limit}
+}
+
+func (l *limitReader) Read(b []byte) (int, error) {
+	if l.exceeded() {
+		return 0, fmt.Errorf("content exceeds %d bytes", l.limit)
+	}
+	// Read one byte past the limit to tell "exactly the limit" from "more".
+	if rest := l.limit - l.n + 1; int64(len(b)) > rest {
+		b = b[:rest]
+	}
+	n, err := l.r.Read(b)
+	l.n += int64(n)
+	if l.exceeded() {
+		return n - 1, fmt.Errorf("content exceeds %d bytes", l.limit)
+	}
+	return n, err
+}
+
+// exceeded reports whether more than the limit was read. It is false for a
+// nil limitReader.
+func (l *limitReader) exceeded() bool {
+	return l != nil && l.n > l.limit
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerMaxSize(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			return
+		}
+		uploads++
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+	defer viper.Set("max_upload_size", "")
+
+	dir := t.TempDir()
+	big := filepath.Join(dir, "big.log")
+	if err := os.WriteFile(big, bytes.Repeat([]byte("x"), 2000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	small := filepath.Join(dir, "small.log")
+	if err := os.WriteFile(small, []byte("small"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		flags  map[string]string
+		config string
+		stdin  string
+	}{
+		{name: "file", args: []string{big}, flags: map[string]string{"max-size": "1KB"}},
+		{name: "config default", args: []string{big}, config: "1KB"},
+		{name: "several files", args: []string{small, big}, flags: map[string]string{"max-size": "1KB"}},
+		{name: "content", flags: map[string]string{"content": strings.Repeat("x", 2000), "max-size": "1KB"}},
+		{name: "stdin", stdin: strings.Repeat("x", 2000), flags: map[string]string{"max-size": "1KB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads = 0
+			viper.Set("max_upload_size", tt.config)
+			cmd := NewUploadCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.stdin))
+			for name, value := range tt.flags {
+				_ = cmd.Flags().Set(name, value)
+			}
+			var usageErr *UsageError
+			err := Upload(cmd, tt.args)
+			if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "larger than the maximum of 1.0 kB") {
+				t.Errorf("Expected a size limit error, got %v", err)
+			}
+			if uploads != 0 {
+				t.Errorf("Expected nothing to be uploaded, got %d uploads", uploads)
+			}
+		})
+	}
+
+	// --max-size overrides the configured limit.
+	viper.Set("max_upload_size", "1KB")
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("max-size", "10KB")
+	if err := Upload(cmd, []string{big}); err != nil || uploads != 1 {
+		t.Errorf("Expected the upload to go through under --max-size, got %v and %d uploads", err, uploads)
+	}
+
+	viper.Set("max_upload_size", "lots")
+	if err := Upload(NewUploadCmd(), []string{small}); err == nil || !strings.Contains(err.Error(), "max_upload_size") {
+		t.Errorf("Expected an invalid config error, got %v", err)
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	l := &limitReader{r: strings.NewReader("12345"), limit: 5}
+	data, err := io.ReadAll(l)
+	if err != nil || string(data) != "12345" {
+		t.Errorf("Expected content at the limit to pass, got %q, %v", data, err)
+	}
+
+	l = &limitReader{r: strings.NewReader("123456"), limit: 5}
+	data, err = io.ReadAll(l)
+	if err == nil || !l.exceeded() || len(data) > 5 {
+		t.Errorf("Expected content over the limit to fail, got %q, %v", data, err)
+	}
+
+	if l := limitSize(strings.NewReader("123456"), 0); l != nil || l.exceeded() {
+		t.Error("Expected no limit to leave the reader unwrapped")
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// metaSuffix is added to the path of an uploaded file to name its sidecar.
+const metaSuffix = ".0x45.json"
+
+// uploadMeta is the sidecar --save-meta writes: the server's response and
+// enough about the request to find, share or delete the paste later.
+type uploadMeta struct {
+	ID string `json:"id"`
+	*api.UploadResponse
+	Filename   string `json:"filename"`
+	Source     string `json:"source,omitempty"`
+	Private    bool   `json:"private"`
+	Expires    string `json:"expires,omitempty"`
+	ExpiresAt  string `json:"expires_at,omitempty"`
+	UploadedAt string `json:"uploaded_at"`
+	Server     string `json:"server"`
+}
+
+// metaSidecar is where --save-meta writes, for the upload of source.
+type metaSidecar struct {
+	path   string
+	source string
+}
+
+// metaSidecarFlag reads --save-meta and --meta-file, returning nil if
+// neither is set. The sidecar goes next to source, the file or directory
+// being uploaded, unless --meta-file says otherwise; uploads with no local
+// file need --meta-file. An existing sidecar is only overwritten with
+// --force, which is checked before anything is uploaded.
+func metaSidecarFlag(cmd *cobra.Command, source string) (*metaSidecar, error) {
+	saveMeta, err := cmd.Flags().GetBool("save-meta")
+	if err != nil {
+		return nil, err
+	}
+	path, err := cmd.Flags().GetString("meta-file")
+	if err != nil {
+		return nil, err
+	}
+	if !saveMeta && path == "" {
+		return nil, nil
+	}
+
+	if source != "" {
+		if source, err = filepath.Abs(source); err != nil {
+			return nil, err
+		}
+	}
+	if path == "" {
+		if source == "" {
+			return nil, usageErrorf("--save-meta needs --meta-file when the upload isn't a local file or directory")
+		}
+		path = source + metaSuffix
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return nil, usageErrorf("%s already exists (use --force to overwrite it)", path)
+	}
+	return &metaSidecar{path: path, source: source}, nil
+}
+
+// save writes the sidecar for a successful upload. It does nothing on a nil
+// sidecar, so callers needn't check whether --save-meta was given.
+func (m *metaSidecar) save(resp *api.UploadResponse, opts api.UploadOptions) error {
+	if m == nil || !resp.Success {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	id, _ := splitPasteRef(resp.URL)
+	meta := uploadMeta{
+		ID:             id,
+		UploadResponse: resp,
+		Filename:       opts.Filename,
+		Source:         m.source,
+		Private:        opts.Private,
+		Expires:        opts.Expires,
+		UploadedAt:     now.Format(time.RFC3339),
+		Server:         client.BaseURL(),
+	}
+	if d, err := time.ParseDuration(opts.Expires); err == nil {
+		meta.ExpiresAt = now.Add(d).Format(time.RFC3339)
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding upload metadata: %w", err)
+	}
+	// The sidecar holds the delete URL, and the key of encrypted pastes.
+	if err := os.WriteFile(m.path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("error saving upload metadata: %w", err)
+	}
+	return nil
+}
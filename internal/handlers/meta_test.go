@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+)
+
+func TestUploadHandlerSaveMeta(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := func(args []string, flags ...string) error {
+		cmd := NewUploadCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		for i := 0; i < len(flags); i += 2 {
+			_ = cmd.Flags().Set(flags[i], flags[i+1])
+		}
+		return Upload(cmd, args)
+	}
+
+	if err := upload([]string{file}, "save-meta", "true", "expires", "7d"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file + metaSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta uploadMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatal(err)
+	}
+	if meta.ID != "abc123" || meta.URL != "https://0x45.st/abc123" || meta.DeleteURL != "https://0x45.st/delete/abc123" {
+		t.Errorf("Expected the response in the sidecar, got %s", data)
+	}
+	if meta.Filename != "notes.txt" || meta.Source != file || meta.Expires != "168h" {
+		t.Errorf("Expected the request details in the sidecar, got %s", data)
+	}
+	if expiresAt, err := time.Parse(time.RFC3339, meta.ExpiresAt); err != nil || time.Until(expiresAt) < 167*time.Hour {
+		t.Errorf("Expected an expiry time a week from now, got %q", meta.ExpiresAt)
+	}
+
+	var usageErr *UsageError
+	if err := upload([]string{file}, "save-meta", "true"); !errors.As(err, &usageErr) {
+		t.Errorf("Expected an existing sidecar to be a usage error, got %v", err)
+	}
+	if err := upload([]string{file}, "save-meta", "true", "force", "true"); err != nil {
+		t.Errorf("Expected --force to overwrite the sidecar, got %v", err)
+	}
+
+	if err := upload(nil, "content", "hi", "save-meta", "true"); !errors.As(err, &usageErr) {
+		t.Errorf("Expected --save-meta without a file to need --meta-file, got %v", err)
+	}
+	metaFile := filepath.Join(dir, "content.json")
+	if err := upload(nil, "content", "hi", "meta-file", metaFile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(metaFile); err != nil {
+		t.Errorf("Expected --meta-file to be written, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// singleUploadFlags only make sense for one upload at a time.
+var singleUploadFlags = []string{"content", "stdin-name", "archive", "filename", "dedupe", "force", "qr", "field", "dry-run", "title", "encrypt", "if-newer", "from-url", "append", "resumable", "chunk-size", "replace", "save-meta", "meta-file", "base64"}
+
+// uploadFileResult reports the outcome of uploading one of several files.
+type uploadFileResult struct {
+	File      string `json:"file"`
+	Success   bool   `json:"success"`
+	URL       string `json:"url,omitempty"`
+	DeleteURL string `json:"delete_url,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// uploadFiles uploads each of paths as its own paste, up to --concurrency at
+// a time. Results are reported in the order the files were given, and a
+// failed upload doesn't stop the others.
+func uploadFiles(cmd *cobra.Command, paths []string) error {
+	for _, name := range singleUploadFlags {
+		if cmd.Flags().Changed(name) {
+			return usageErrorf("--%s can only be used when uploading a single file", name)
+		}
+	}
+
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return err
+	}
+	if concurrency < 1 {
+		return usageErrorf("invalid concurrency %d: must be 1 or more", concurrency)
+	}
+
+	private, err := cmd.Flags().GetBool("private")
+	if err != nil {
+		return err
+	}
+	if err := checkPrivateUpload(private); err != nil {
+		return err
+	}
+
+	expires, err := resolveExpiry(cmd)
+	if err != nil {
+		return err
+	}
+
+	copyURL, err := shouldCopy(cmd)
+	if err != nil {
+		return err
+	}
+
+	noProgress, err := cmd.Flags().GetBool("no-progress")
+	if err != nil {
+		return err
+	}
+
+	mimeType, err := mimeTypeFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	allowEmpty, err := cmd.Flags().GetBool("allow-empty")
+	if err != nil {
+		return err
+	}
+
+	langExt, err := langFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	password, err := passwordFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := formatFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	// The limit applies to all the uploads together, not to each one.
+	limiter, err := limitRateFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	maxSize, err := maxSizeFlag(cmd, 0)
+	if err != nil {
+		return err
+	}
+
+	// Everything is checked up front so a typo doesn't leave half the files
+	// uploaded.
+	var total int64
+	for _, path := range paths {
+		if path == "-" {
+			return usageErrorf("stdin can't be uploaded together with other files")
+		}
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			return usageErrorf("file does not exist: %s", path)
+		}
+		if err != nil {
+			return fmt.Errorf("error getting file info: %w", err)
+		}
+		if info.IsDir() {
+			return usageErrorf("%s is a directory (directories can only be uploaded one at a time with --archive)", path)
+		}
+		if info.Size() == 0 && !allowEmpty {
+			return fmt.Errorf("%s: %w", path, errEmptyUpload)
+		}
+		if err := checkSize(maxSize, info.Size(), path); err != nil {
+			return err
+		}
+		total += info.Size()
+	}
+
+	// Concurrent uploads share one bar for all the bytes, so their output
+	// doesn't interleave.
+	var progress *progressReader
+	if !noProgress && !jsonOutput() && isTerminal() && total > 0 {
+		progress = newProgressReader(nil, total, cmd.ErrOrStderr())
+	}
+
+	results := make([]uploadFileResult, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				opts := api.UploadOptions{
+					Filename:  filepath.Base(paths[i]),
+					Private:   private,
+					Expires:   expires,
+					MimeType:  mimeType,
+					Extension: langExt,
+					Password:  password,
+				}
+				results[i] = uploadOneFile(paths[i], opts, limiter, progress)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if progress != nil {
+		progress.Finish()
+	}
+
+	failed := 0
+	var urls []string
+	for _, result := range results {
+		if result.Success {
+			urls = append(urls, result.URL)
+		} else {
+			failed++
+		}
+	}
+
+	if copyURL && len(urls) > 0 {
+		copyToClipboard(cmd, strings.Join(urls, "\n"))
+	}
+
+	if jsonOutput() {
+		if err := printJSON(cmd, results); err != nil {
+			return err
+		}
+	} else if tmpl != nil {
+		if err := printTemplateEach(cmd, tmpl, results); err != nil {
+			return err
+		}
+	} else if quietOutput() {
+		for _, result := range results {
+			if result.Success {
+				fmt.Fprintln(cmd.OutOrStdout(), result.URL)
+			} else {
+				fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatError(fmt.Sprintf("%s: %s", result.File, result.Error)))
+			}
+		}
+	} else {
+		out := cmd.OutOrStdout()
+		for _, result := range results {
+			if !result.Success {
+				fmt.Fprintln(out, theme.FormatError(fmt.Sprintf("%s: %s", result.File, result.Error)))
+				continue
+			}
+			fmt.Fprintf(out, "%s: %s\n", result.File, result.URL)
+			if result.DeleteURL != "" {
+				fmt.Fprintln(out, "  Delete URL:", result.DeleteURL)
+			}
+		}
+		fmt.Fprintln(out, theme.FormatSuccess(fmt.Sprintf("Uploaded %d of %d files", len(urls), len(paths))))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d uploads failed", failed, len(paths))
+	}
+	return nil
+}
+
+// uploadOneFile uploads path, counting its bytes towards progress and pacing
+// them with limiter if set.
+func uploadOneFile(path string, opts api.UploadOptions, limiter *rateLimiter, progress *progressReader) uploadFileResult {
+	result := uploadFileResult{File: path}
+	logging.Infof("Uploading %s", path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		result.Error = fmt.Sprintf("error opening file: %v", err)
+		return result
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		result.Error = fmt.Sprintf("error getting file info: %v", err)
+		return result
+	}
+
+	body := limiter.reader(file)
+	if progress != nil {
+		body = &progressPart{r: body, p: progress}
+	}
+
+	resp, err := client.UploadReader(body, info.Size(), opts)
+	switch {
+	case err != nil:
+		result.Error = wrapAPIError("error uploading file", err).Error()
+	case !resp.Success:
+		result.Error = "error uploading file: " + resp.Error
+	default:
+		result.Success = true
+		result.URL = resp.URL
+		result.DeleteURL = resp.DeleteURL
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerMultipleFiles(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		name := r.Header.Get("X-Filename")
+		// Earlier files finish last, so results arrive out of order.
+		if name == "a.txt" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if name == "bad.txt" {
+			http.Error(w, "storage full", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/" + strings.TrimSuffix(name, ".txt")})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("content of "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("concurrency", "2")
+
+	if err := Upload(cmd, files); err != nil {
+		t.Fatal(err)
+	}
+	if maxInFlight != 2 {
+		t.Errorf("Expected 2 uploads at once, got %d", maxInFlight)
+	}
+	output := buf.String()
+	a := strings.Index(output, "a.txt: https://0x45.st/a")
+	d := strings.Index(output, "d.txt: https://0x45.st/d")
+	if a < 0 || d < 0 || a > d {
+		t.Errorf("Expected results in file order, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Uploaded 4 of 4 files") {
+		t.Errorf("Expected a summary, got:\n%s", output)
+	}
+
+	// One failure doesn't stop the rest.
+	bad := filepath.Join(dir, "bad.txt")
+	if err := os.WriteFile(bad, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	viper.Set("json", true)
+	defer viper.Set("json", false)
+	err := Upload(cmd, []string{files[0], bad, files[1]})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 uploads failed") {
+		t.Errorf("Expected 1 of 3 uploads to fail, got %v", err)
+	}
+	var results []uploadFileResult
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("Expected JSON results, got %q: %v", buf.String(), err)
+	}
+	if len(results) != 3 || !results[0].Success || results[1].Success || !results[2].Success {
+		t.Errorf("Expected only the second upload to fail, got %+v", results)
+	}
+	if results[1].File != bad || !strings.Contains(results[1].Error, "storage full") {
+		t.Errorf("Expected the failure to be reported for %s, got %+v", bad, results[1])
+	}
+}
+
+func TestUploadHandlerMultipleFilesValidation(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		flags map[string]string
+		args  []string
+	}{
+		{"zero concurrency", map[string]string{"concurrency": "0"}, []string{a, b}},
+		{"single-file flag", map[string]string{"filename": "x.txt"}, []string{a, b}},
+		{"stdin", nil, []string{a, "-"}},
+		{"missing file", nil, []string{a, filepath.Join(dir, "missing.txt")}},
+		{"directory", nil, []string{a, dir}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewUploadCmd()
+			for name, value := range tt.flags {
+				_ = cmd.Flags().Set(name, value)
+			}
+			var usageErr *UsageError
+			if err := Upload(cmd, tt.args); !errors.As(err, &usageErr) {
+				t.Errorf("Expected a usage error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+// openBrowser is swapped out in tests.
+var openBrowser = func(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
+
+func NewOpenCmd() *cobra.Command {
+	var raw bool
+
+	cmd := &cobra.Command{
+		Use:   "open [id]",
+		Short: "Open a paste or shortened URL in the browser",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Open,
+	}
+
+	cmd.Flags().BoolVar(&raw, "raw", false, "Open the raw content of a paste")
+
+	return cmd
+}
+
+func Open(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+
+	raw, err := cmd.Flags().GetBool("raw")
+	if err != nil {
+		return err
+	}
+
+	id := args[0]
+	found, err := client.Exists(id, raw)
+	if err != nil {
+		return wrapAPIError("error looking up "+id, err)
+	}
+	if !found {
+		if raw {
+			return notFoundErrorf("paste not found: %s", id)
+		}
+		return notFoundErrorf("paste or URL not found: %s", id)
+	}
+
+	target := strings.TrimRight(viper.GetString("api_url"), "/") + "/" + url.PathEscape(id)
+	if raw {
+		target += "/raw"
+	}
+
+	if err := openBrowser(target); err != nil {
+		return fmt.Errorf("could not open browser: %w (URL: %s)", err, target)
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, map[string]string{"url": target})
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("Opened:"), theme.FormatURL(target))
+	return nil
+}
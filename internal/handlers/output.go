@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// jsonOutput reports whether the user asked for machine-readable output.
+func jsonOutput() bool {
+	return viper.GetBool("json")
+}
+
+// quietOutput reports whether the user asked for only the essential output,
+// such as the URL of an upload.
+func quietOutput() bool {
+	return viper.GetBool("quiet")
+}
+
+// prettyJSON reports whether --json output is indented. Unless the
+// json_pretty or json_compact setting says otherwise, it is indented for a
+// terminal and compact when piped or written to a file, so large exports
+// stay small.
+func prettyJSON(cmd *cobra.Command) bool {
+	switch {
+	case viper.GetBool("json_compact"):
+		return false
+	case viper.GetBool("json_pretty"):
+		return true
+	}
+	f, ok := cmd.OutOrStdout().(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+// printJSON writes v to the command's stdout as JSON, indented or compact
+// as prettyJSON decides.
+func printJSON(cmd *cobra.Command, v any) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	if prettyJSON(cmd) {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
+
+// printJSONLines writes each of items to the command's stdout as compact
+// JSON on its own line.
+func printJSONLines[T any](cmd *cobra.Command, items []T) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupField follows a dotted path of JSON field names through v, as v
+// would be encoded by --json. Numeric segments index into slices. A nil
+// pointer along the way yields nil rather than an error.
+func lookupField(v any, path string) (any, error) {
+	cur := reflect.ValueOf(v)
+	for _, seg := range strings.Split(path, ".") {
+		var ok bool
+		if cur, ok = indirect(cur); !ok {
+			return nil, nil
+		}
+
+		switch cur.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(cur, seg)
+			if !ok {
+				return nil, usageErrorf("no field %q in response (available: %s)", path, strings.Join(jsonFieldNames(cur.Type()), ", "))
+			}
+			cur = field
+		case reflect.Map:
+			value := cur.MapIndex(reflect.ValueOf(seg))
+			if !value.IsValid() {
+				return nil, usageErrorf("no field %q in response", path)
+			}
+			cur = value
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= cur.Len() {
+				return nil, usageErrorf("no field %q in response", path)
+			}
+			cur = cur.Index(i)
+		default:
+			return nil, usageErrorf("no field %q in response", path)
+		}
+	}
+
+	cur, ok := indirect(cur)
+	if !ok {
+		return nil, nil
+	}
+	return cur.Interface(), nil
+}
+
+// indirect follows pointers and interfaces, reporting false on a nil one.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// jsonField returns the field of struct v encoded under name.
+func jsonField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonFieldNames lists the JSON names of a struct type's fields.
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// checkField validates a --field path against the type of response a
+// command will print, so a typo is caught before the request is made.
+func checkField(path string, zero any) error {
+	if path == "" {
+		return nil
+	}
+	_, err := lookupField(zero, path)
+	return err
+}
+
+// printField writes the value at path in v on its own line: strings as is,
+// nil as an empty line, and anything else as compact JSON.
+func printField(cmd *cobra.Command, v any, path string) error {
+	value, err := lookupField(v, path)
+	if err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		fmt.Fprintln(cmd.OutOrStdout())
+	case string:
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	}
+	return nil
+}
+
+// formatFlag parses the --format template of cmd, if it has one, so a
+// broken template is reported before the request is made.
+func formatFlag(cmd *cobra.Command) (*template.Template, error) {
+	flag := cmd.Flags().Lookup("format")
+	if flag == nil || flag.Value.String() == "" {
+		return nil, nil
+	}
+	if jsonOutput() {
+		return nil, usageErrorf("--format can't be combined with --json")
+	}
+	if flag := cmd.Flags().Lookup("field"); flag != nil && flag.Changed {
+		return nil, usageErrorf("--format can't be combined with --field")
+	}
+	tmpl, err := template.New("format").Parse(flag.Value.String())
+	if err != nil {
+		return nil, usageErrorf("invalid --format template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// printTemplate renders v with tmpl, followed by a newline unless the
+// template ends with one. Templates see v as --json would print it, so
+// fields go by their JSON names: {{.url}}, {{.delete_url}}. Fields the
+// server left out are missing, which {{if .title}} treats as false.
+func printTemplate(cmd *cobra.Command, tmpl *template.Template, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields any
+	if err := dec.Decode(&fields); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, fields); err != nil {
+		return fmt.Errorf("error rendering --format template: %w", err)
+	}
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err = cmd.OutOrStdout().Write(out.Bytes())
+	return err
+}
+
+// printTemplateEach renders each of items with tmpl.
+func printTemplateEach[T any](cmd *cobra.Command, tmpl *template.Template, items []T) error {
+	for _, item := range items {
+		if err := printTemplate(cmd, tmpl, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+// stdoutIsTerminal is swapped out in tests.
+var stdoutIsTerminal = isTerminal
+
+// pageInfo describes where a single fetched page sits in a listing.
+type pageInfo struct {
+	Page int
+	// Pages is the number of pages, or 0 if the server didn't say how many
+	// items there are.
+	Pages   int
+	HasNext bool
+}
+
+// newPageInfo works out the page position from a list response. Without a
+// total from the server, a full page is taken to mean there may be another.
+func newPageInfo(page, perPage, total, limit, received int) pageInfo {
+	if perPage <= 0 {
+		perPage = limit
+	}
+	info := pageInfo{Page: max(page, 1)}
+	if perPage <= 0 {
+		return info
+	}
+	if total > 0 {
+		info.Pages = (total + perPage - 1) / perPage
+		info.HasNext = info.Page < info.Pages
+		return info
+	}
+	info.HasNext = received >= perPage
+	return info
+}
+
+// printPageFooter prints the page position under a listing, with the
+// commands that show the pages either side of it.
+func printPageFooter(cmd *cobra.Command, args []string, info pageInfo) {
+	out := cmd.OutOrStdout()
+	position := fmt.Sprintf("Page %d", info.Page)
+	if info.Pages > 0 {
+		position = fmt.Sprintf("Page %d of %d", info.Page, info.Pages)
+	}
+	fmt.Fprintln(out, theme.Subtitle.Render(position))
+	if info.HasNext {
+		fmt.Fprintln(out, theme.FormatKeyValue("Next page", pageCommand(cmd, args, info.Page+1)))
+	}
+	if info.Page > 1 {
+		fmt.Fprintln(out, theme.FormatKeyValue("Previous page", pageCommand(cmd, args, info.Page-1)))
+	}
+}
+
+// pageCommand returns the command line that repeats this listing at page,
+// keeping the flags that were given. Only the command's own flags are
+// repeated: inherited ones such as --api-key and --header can hold secrets,
+// and the footer is printed to stdout.
+func pageCommand(cmd *cobra.Command, args []string, page int) string {
+	parts := []string{cmd.CommandPath()}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || f.Name == "page" || f.Name == "prompt" {
+			return
+		}
+		if f.Value.Type() == "bool" {
+			if f.Value.String() == "true" {
+				parts = append(parts, "--"+f.Name)
+			}
+			return
+		}
+		// Repeatable flags are given once per value.
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range slice.GetSlice() {
+				parts = append(parts, "--"+f.Name+"="+shellQuote(v))
+			}
+			return
+		}
+		parts = append(parts, "--"+f.Name+"="+shellQuote(f.Value.String()))
+	})
+	parts = append(parts, "--page", strconv.Itoa(page))
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if it has anything but plain
+// characters in it.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.,:/=@+%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// showNextPage asks whether to fetch the next page inline, for --prompt. It
+// only asks when both stdin and stdout are terminals, so piped output is
+// never held up.
+func showNextPage(cmd *cobra.Command, info pageInfo) bool {
+	prompt, _ := cmd.Flags().GetBool("prompt")
+	if !prompt || !info.HasNext || !stdoutIsTerminal() || !canPrompt(cmd) {
+		return false
+	}
+	return confirm(cmd, "Show next page?")
+}
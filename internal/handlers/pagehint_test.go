@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestListPageFooter(t *testing.T) {
+	const total = 5
+	var pages []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		pages = append(pages, page)
+
+		resp := api.ListResponse[api.PasteListItem]{Success: true}
+		resp.Data.Total = total
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			resp.Data.Items = append(resp.Data.Items, api.PasteListItem{Id: strconv.Itoa(i), Filename: "file" + strconv.Itoa(i) + ".txt"})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	oldCanPrompt, oldStdoutIsTerminal := canPrompt, stdoutIsTerminal
+	defer func() { canPrompt, stdoutIsTerminal = oldCanPrompt, oldStdoutIsTerminal }()
+	canPrompt = func(*cobra.Command) bool { return true }
+	stdoutIsTerminal = func() bool { return true }
+
+	list := func(input string, flags ...string) string {
+		t.Helper()
+		cmd := NewListCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		cmd.SetIn(strings.NewReader(input))
+		_ = cmd.Flags().Set("per-page", "2")
+		for i := 0; i < len(flags); i += 2 {
+			_ = cmd.Flags().Set(flags[i], flags[i+1])
+		}
+		if err := List(cmd, []string{"pastes"}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	output := list("", "page", "2", "filter", "my file")
+	for _, want := range []string{
+		"Page 2 of 3",
+		"list pastes --filter='my file' --per-page=2 --page 3",
+		"list pastes --filter='my file' --per-page=2 --page 1",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the footer to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Show next page?") {
+		t.Errorf("Expected no prompt without --prompt, got:\n%s", output)
+	}
+
+	pages = nil
+	output = list("y\n", "prompt", "true")
+	if len(pages) != 2 || !strings.Contains(output, "file2.txt") || !strings.Contains(output, "Page 2 of 3") {
+		t.Errorf("Expected answering yes to show page 2, fetched %v:\n%s", pages, output)
+	}
+	if strings.Contains(output, "--prompt") {
+		t.Errorf("Expected the hint to leave out --prompt, got:\n%s", output)
+	}
+
+	output = list("", "page", "3")
+	if strings.Contains(output, "Next page") || !strings.Contains(output, "Page 3 of 3") {
+		t.Errorf("Expected no next page hint on the last page, got:\n%s", output)
+	}
+
+	viper.Set("json", true)
+	defer viper.Set("json", false)
+	if output := list(""); strings.Contains(output, "Next page") {
+		t.Errorf("Expected no footer with --json, got:\n%s", output)
+	}
+}
+
+func TestPageCommand(t *testing.T) {
+	root := &cobra.Command{Use: "0x45"}
+	root.PersistentFlags().String("api-key", "", "")
+	root.PersistentFlags().StringArray("header", nil, "")
+	cmd := NewListCmd()
+	cmd.Flags().StringArray("tag", nil, "")
+	root.AddCommand(cmd)
+
+	_ = root.PersistentFlags().Set("api-key", "SUPERSECRETKEY123")
+	_ = root.PersistentFlags().Set("header", "Authorization: Bearer OTHERSECRET")
+	_ = cmd.Flags().Set("tag", "a b")
+	_ = cmd.Flags().Set("tag", "c")
+	_ = cmd.Flags().Set("show-raw", "true")
+	_ = cmd.Flags().Set("page", "4")
+
+	got := pageCommand(cmd, []string{"pastes"}, 5)
+	want := "0x45 list pastes --show-raw --tag='a b' --tag=c --page 5"
+	if got != want {
+		t.Errorf("pageCommand() = %q, want %q", got, want)
+	}
+	if strings.Contains(got, "SUPERSECRETKEY123") || strings.Contains(got, "OTHERSECRET") {
+		t.Errorf("Expected inherited secrets to be left out, got %q", got)
+	}
+}
+
+func TestNewPageInfo(t *testing.T) {
+	tests := []struct {
+		page, perPage, total, limit, received int
+		want                                  pageInfo
+	}{
+		{1, 10, 45, 0, 10, pageInfo{Page: 1, Pages: 5, HasNext: true}},
+		{5, 10, 45, 0, 5, pageInfo{Page: 5, Pages: 5}},
+		{1, 0, 30, 20, 20, pageInfo{Page: 1, Pages: 2, HasNext: true}},
+		{2, 10, 0, 0, 10, pageInfo{Page: 2, HasNext: true}},
+		{2, 10, 0, 0, 3, pageInfo{Page: 2}},
+		{1, 0, 0, 0, 8, pageInfo{Page: 1}},
+	}
+	for _, tt := range tests {
+		if got := newPageInfo(tt.page, tt.perPage, tt.total, tt.limit, tt.received); got != tt.want {
+			t.Errorf("newPageInfo(%d, %d, %d, %d, %d) = %+v, want %+v", tt.page, tt.perPage, tt.total, tt.limit, tt.received, got, tt.want)
+		}
+	}
+}
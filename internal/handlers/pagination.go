@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/logging"
+	"github.com/watzon/0x45-cli/internal/theme"
+
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+const (
+	// maxPerPage is the largest page size the server allows, and the size
+	// requested when fetching everything.
+	maxPerPage = 100
+	// maxPages stops runaway pagination if the server keeps returning items.
+	maxPages = 1000
+)
+
+// sortFields are the --sort values the server accepts.
+var sortFields = []string{"created_at", "expires_at", "clicks"}
+
+// validateSort checks a --sort value against sortFields. Empty leaves the
+// order to the server.
+func validateSort(field string) error {
+	if field == "" || slices.Contains(sortFields, field) {
+		return nil
+	}
+	return usageErrorf("invalid sort field %q: must be one of %s", field, strings.Join(sortFields, ", "))
+}
+
+// fetchAll walks every page of a list endpoint and returns the combined items.
+// It stops on an empty or short page, once Total items have been collected,
+// or after maxPages requests.
+func fetchAll[T any](fetch func(opts api.ListOptions) (*api.ListResponse[T], error), opts api.ListOptions) (*api.ListResponse[T], error) {
+	if opts.PerPage <= 0 || opts.PerPage > maxPerPage {
+		opts.PerPage = maxPerPage
+	}
+
+	result := &api.ListResponse[T]{Success: true}
+	for opts.Page = 1; opts.Page <= maxPages; opts.Page++ {
+		logging.Debugf("Fetching page %d", opts.Page)
+		resp, err := fetch(opts)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success {
+			return nil, fmt.Errorf("%s", resp.Error)
+		}
+
+		result.Data.Items = append(result.Data.Items, resp.Data.Items...)
+		if resp.Data.Total > 0 {
+			result.Data.Total = resp.Data.Total
+		}
+
+		if len(resp.Data.Items) < opts.PerPage {
+			break
+		}
+		if result.Data.Total > 0 && len(result.Data.Items) >= result.Data.Total {
+			break
+		}
+	}
+
+	if result.Data.Total == 0 {
+		result.Data.Total = len(result.Data.Items)
+	}
+	result.Data.Page = 1
+	result.Data.Limit = len(result.Data.Items)
+	return result, nil
+}
+
+// listOptions validates the --page and --per-page values of cmd. A page size
+// of 0 leaves it to the server; one above maxPerPage is allowed, since
+// servers may differ, but warned about.
+func listOptions(cmd *cobra.Command, page, perPage int, order string) (api.ListOptions, error) {
+	if page < 1 {
+		return api.ListOptions{}, usageErrorf("invalid page %d: must be 1 or more", page)
+	}
+	if perPage < 0 {
+		return api.ListOptions{}, usageErrorf("invalid per-page %d: must be 0 (server default) or more", perPage)
+	}
+	if perPage > maxPerPage {
+		fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatWarning(fmt.Sprintf("--per-page %d is above the server maximum of %d, fewer items may be returned", perPage, maxPerPage)))
+	}
+	return api.ListOptions{Page: page, PerPage: perPage, Order: order}, nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// askPassword is the --password value that asks for the password at a
+// prompt instead, keeping it out of shell history and process listings.
+const askPassword = "-"
+
+// readPassword reads a line from the terminal without echoing it; swapped
+// out in tests.
+var readPassword = func(cmd *cobra.Command) (string, error) {
+	f, ok := cmd.InOrStdin().(*os.File)
+	if !ok {
+		return "", fmt.Errorf("stdin is not a terminal")
+	}
+	password, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(cmd.ErrOrStderr())
+	return string(password), err
+}
+
+// passwordFlag returns the --password to send, prompting for it when given
+// as "-". With confirm set the password is asked for twice, so a typo
+// doesn't lock the paste.
+func passwordFlag(cmd *cobra.Command, confirm bool) (string, error) {
+	password, err := cmd.Flags().GetString("password")
+	if err != nil || password != askPassword {
+		return password, err
+	}
+	if !canPrompt(cmd) {
+		return "", usageErrorf("--password - needs a terminal to prompt for the password")
+	}
+	return promptPassword(cmd, confirm)
+}
+
+// ReadSecret reads a secret such as an API key, at a prompt without echo
+// when stdin is a terminal and otherwise from the first line of stdin.
+// Only that line is consumed, so content piped after it is left for the
+// command itself. Surrounding whitespace, including the newline, is
+// trimmed.
+func ReadSecret(cmd *cobra.Command, prompt string) (string, error) {
+	var secret string
+	if canPrompt(cmd) {
+		fmt.Fprint(cmd.ErrOrStderr(), prompt)
+		line, err := readPassword(cmd)
+		if err != nil {
+			return "", err
+		}
+		secret = line
+	} else {
+		line, err := readLine(cmd.InOrStdin())
+		if err != nil {
+			return "", err
+		}
+		secret = line
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", usageErrorf("no value was given")
+	}
+	return secret, nil
+}
+
+// readLine reads up to and including the first newline one byte at a time,
+// so that nothing past it is buffered away from later readers.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			line = append(line, b[0])
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+		}
+		if err == io.EOF {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func promptPassword(cmd *cobra.Command, confirm bool) (string, error) {
+	fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+	password, err := readPassword(cmd)
+	if err != nil {
+		return "", fmt.Errorf("error reading password: %w", err)
+	}
+	if password == "" {
+		return "", usageErrorf("the password can't be empty")
+	}
+	if confirm {
+		fmt.Fprint(cmd.ErrOrStderr(), "Confirm password: ")
+		again, err := readPassword(cmd)
+		if err != nil {
+			return "", fmt.Errorf("error reading password: %w", err)
+		}
+		if again != password {
+			return "", usageErrorf("passwords don't match")
+		}
+	}
+	return password, nil
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// fakePasswords answers password prompts with answers in turn.
+func fakePasswords(t *testing.T, answers ...string) {
+	t.Helper()
+	oldCanPrompt, oldReadPassword := canPrompt, readPassword
+	canPrompt = func(*cobra.Command) bool { return true }
+	readPassword = func(*cobra.Command) (string, error) {
+		if len(answers) == 0 {
+			t.Fatal("Unexpected password prompt")
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	t.Cleanup(func() { canPrompt, readPassword = oldCanPrompt, oldReadPassword })
+}
+
+func TestUploadHandlerPassword(t *testing.T) {
+	var password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		password = r.Header.Get(api.PasswordHeader)
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("content", "secret stuff")
+	_ = cmd.Flags().Set("password", "hunter2")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if password != "hunter2" {
+		t.Errorf("Expected the password header, got %q", password)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("Expected the password not to be echoed, got %s", buf.String())
+	}
+
+	fakePasswords(t, "s3cret", "s3cret")
+	_ = cmd.Flags().Set("password", "-")
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if password != "s3cret" {
+		t.Errorf("Expected the prompted password, got %q", password)
+	}
+
+	fakePasswords(t, "s3cret", "typo")
+	var usageErr *UsageError
+	if err := Upload(cmd, nil); !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "don't match") {
+		t.Errorf("Expected mismatched passwords to be refused, got %v", err)
+	}
+
+	cmd = NewUploadCmd()
+	_ = cmd.Flags().Set("password", "-")
+	if err := Upload(cmd, []string{"-"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error prompting while reading stdin, got %v", err)
+	}
+}
+
+func TestGetHandlerPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(api.PasswordHeader) != "hunter2" {
+			http.Error(w, "password required", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("secret stuff"))
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewGetCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("password", "hunter2")
+	if err := Get(cmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "secret stuff" {
+		t.Errorf("Expected the paste content, got %q", buf.String())
+	}
+
+	_ = cmd.Flags().Set("password", "wrong")
+	if err := Get(cmd, []string{"abc123"}); err == nil || !strings.Contains(err.Error(), "wrong password") {
+		t.Errorf("Expected a wrong password error, got %v", err)
+	}
+
+	// Without --password, a protected paste prompts for it.
+	fakePasswords(t, "hunter2")
+	cmd = NewGetCmd()
+	buf.Reset()
+	cmd.SetOut(&buf)
+	if err := Get(cmd, []string{"abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "secret stuff" {
+		t.Errorf("Expected the paste content after prompting, got %q", buf.String())
+	}
+}
+
+func TestReadSecret(t *testing.T) {
+	oldCanPrompt := canPrompt
+	canPrompt = func(*cobra.Command) bool { return false }
+	defer func() { canPrompt = oldCanPrompt }()
+
+	cmd := &cobra.Command{}
+	stdin := strings.NewReader("  secret-key\r\nrest of the upload\n")
+	cmd.SetIn(stdin)
+	key, err := ReadSecret(cmd, "API key: ")
+	if err != nil || key != "secret-key" {
+		t.Errorf("Expected the trimmed first line, got %q, %v", key, err)
+	}
+	if rest, _ := io.ReadAll(stdin); string(rest) != "rest of the upload\n" {
+		t.Errorf("Expected the rest of stdin to be left unread, got %q", rest)
+	}
+
+	cmd.SetIn(strings.NewReader("no-newline"))
+	if key, err := ReadSecret(cmd, "API key: "); err != nil || key != "no-newline" {
+		t.Errorf("Expected a key without a newline to be read, got %q, %v", key, err)
+	}
+
+	cmd.SetIn(strings.NewReader("\n"))
+	var usageErr *UsageError
+	if _, err := ReadSecret(cmd, "API key: "); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for an empty key, got %v", err)
+	}
+
+	fakePasswords(t, "typed-key")
+	var prompt bytes.Buffer
+	cmd.SetErr(&prompt)
+	if key, err := ReadSecret(cmd, "API key: "); err != nil || key != "typed-key" || prompt.String() != "API key: " {
+		t.Errorf("Expected the key to be prompted for, got %q, %v after %q", key, err, prompt.String())
+	}
+}
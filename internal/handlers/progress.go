@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-isatty"
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+const progressBarWidth = 30
+
+// progressReader wraps an upload body and draws a progress bar as it is read.
+type progressReader struct {
+	r     io.Reader
+	w     io.Writer
+	total int64
+
+	mu   sync.Mutex
+	sent int64
+}
+
+func newProgressReader(r io.Reader, total int64, w io.Writer) *progressReader {
+	return &progressReader{r: r, w: w, total: total}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.add(n)
+	return n, err
+}
+
+// add counts n more bytes sent and redraws the bar. It is safe to call from
+// several goroutines.
+func (p *progressReader) add(n int) {
+	if n <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent += int64(n)
+	p.render()
+}
+
+// progressPart counts reads from one of several uploads towards a shared
+// progress bar.
+type progressPart struct {
+	r io.Reader
+	p *progressReader
+}
+
+func (pp *progressPart) Read(b []byte) (int, error) {
+	n, err := pp.r.Read(b)
+	pp.p.add(n)
+	return n, err
+}
+
+func (p *progressReader) render() {
+	ratio := float64(p.sent) / float64(p.total)
+	if ratio > 1 {
+		ratio = 1
+	}
+
+	filled := int(ratio * progressBarWidth)
+	bar := theme.ProgressFilled.Render(strings.Repeat("█", filled)) +
+		theme.ProgressEmpty.Render(strings.Repeat("░", progressBarWidth-filled))
+
+	fmt.Fprintf(p.w, "\r%s %3.0f%% %s / %s", bar, ratio*100,
+		humanize.Bytes(uint64(p.sent)), humanize.Bytes(uint64(p.total)))
+}
+
+// Finish moves the cursor past the progress bar.
+func (p *progressReader) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sent > 0 {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// isTerminal reports whether stdout is attached to a terminal.
+func isTerminal() bool {
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// pruneResult summarizes a prune for --json.
+type pruneResult struct {
+	Deleted    int            `json:"deleted"`
+	Failed     int            `json:"failed"`
+	FreedBytes int64          `json:"freed_bytes"`
+	Results    []deleteResult `json:"results"`
+}
+
+func NewPruneCmd() *cobra.Command {
+	var olderThan string
+	var expired bool
+	var dryRun bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old or expired pastes",
+		Long: `Delete every paste created longer ago than --older-than, or whose expiry
+has passed with --expired. Given both, pastes matching either are deleted.`,
+		Example: "  0x45 prune --older-than 90d --dry-run\n  0x45 prune --expired --yes",
+		Args:    cobra.NoArgs,
+		RunE:    Prune,
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Delete pastes created longer ago than this (e.g. 90d, 2w)")
+	cmd.Flags().BoolVar(&expired, "expired", false, "Delete pastes whose expiry time has passed")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the pastes that would be deleted without deleting them")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	return cmd
+}
+
+func Prune(cmd *cobra.Command, args []string) error {
+	olderThan, err := cmd.Flags().GetString("older-than")
+	if err != nil {
+		return err
+	}
+
+	expired, err := cmd.Flags().GetBool("expired")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+
+	if olderThan == "" && !expired {
+		return usageErrorf("nothing to prune: pass --older-than, --expired or both")
+	}
+
+	now := time.Now()
+	var cutoff time.Time
+	if olderThan != "" {
+		age, err := parseExpiry(olderThan)
+		if err != nil {
+			return usageErrorf("invalid --older-than duration: %s (e.g. 90d or 2w)", olderThan)
+		}
+		cutoff = now.Add(-age)
+	}
+
+	if !dryRun && !yes && !canPrompt(cmd) {
+		return usageErrorf("refusing to delete without confirmation: pass --yes when not running interactively")
+	}
+
+	resp, err := fetchAll(client.ListPastes, api.ListOptions{})
+	if err != nil {
+		return wrapAPIError("error listing pastes", err)
+	}
+
+	pastes := prunable(resp.Data.Items, cutoff, expired, now)
+	var total int64
+	for _, item := range pastes {
+		total += item.Size
+	}
+
+	if dryRun {
+		if jsonOutput() {
+			return printJSON(cmd, pastes)
+		}
+		for _, item := range pastes {
+			fmt.Fprintln(cmd.OutOrStdout(), describePaste(item))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatWarning(fmt.Sprintf("Would delete %d pastes, freeing %s", len(pastes), humanize.Bytes(uint64(total)))))
+		return nil
+	}
+
+	if len(pastes) == 0 {
+		if jsonOutput() {
+			return printJSON(cmd, pruneResult{Results: []deleteResult{}})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Nothing to prune")
+		return nil
+	}
+
+	if !yes {
+		fmt.Fprintln(cmd.ErrOrStderr(), "About to delete:")
+		for _, item := range pastes {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", describePaste(item))
+		}
+		if !confirm(cmd, fmt.Sprintf("Delete %d pastes (%s)?", len(pastes), humanize.Bytes(uint64(total)))) {
+			fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatWarning("Aborted"))
+			return nil
+		}
+	}
+
+	// As with delete, a failure doesn't stop the rest.
+	result := pruneResult{Results: make([]deleteResult, 0, len(pastes))}
+	for _, item := range pastes {
+		deleted := deleteResult{Id: item.Id}
+		resp, err := client.Delete(pasteDeleteID(item))
+		switch {
+		case err != nil:
+			deleted.Error = wrapAPIError("error deleting paste", err).Error()
+		case !resp.Success:
+			deleted.Error = "error deleting paste: " + resp.Error
+		default:
+			deleted.Success = true
+			deleted.Message = resp.Message
+		}
+		if deleted.Success {
+			result.Deleted++
+			result.FreedBytes += item.Size
+		} else {
+			result.Failed++
+			if !jsonOutput() {
+				fmt.Fprintln(cmd.ErrOrStderr(), theme.FormatError(fmt.Sprintf("%s: %s", item.Id, deleted.Error)))
+			}
+		}
+		result.Results = append(result.Results, deleted)
+	}
+
+	if jsonOutput() {
+		if err := printJSON(cmd, result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess(fmt.Sprintf("Deleted %d of %d pastes, freeing %s", result.Deleted, len(pastes), humanize.Bytes(uint64(result.FreedBytes)))))
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d deletions failed", result.Failed, len(pastes))
+	}
+	return nil
+}
+
+// prunable picks the pastes created before cutoff, unless it is zero, and
+// with expired set those whose expiry is past. Pastes with unparseable
+// times are kept.
+func prunable(items []api.PasteListItem, cutoff time.Time, expired bool, now time.Time) []api.PasteListItem {
+	var matched []api.PasteListItem
+	for _, item := range items {
+		created, err := time.Parse(time.RFC3339, item.CreatedAt)
+		old := !cutoff.IsZero() && err == nil && created.Before(cutoff)
+
+		gone := false
+		if expired && item.ExpiresAt != nil {
+			expiresAt, err := time.Parse(time.RFC3339, *item.ExpiresAt)
+			gone = err == nil && expiresAt.Before(now)
+		}
+
+		if old || gone {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// describePaste is a one-line summary of a paste for prompts and previews.
+func describePaste(item api.PasteListItem) string {
+	return fmt.Sprintf("%s  %s  %s  %s", item.Id, formatTableTime(&item.CreatedAt), humanize.Bytes(uint64(item.Size)), item.Filename)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestPruneHandler(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) string { return now.Add(-d).Format(time.RFC3339) }
+	past := ago(time.Hour)
+	future := now.Add(time.Hour).Format(time.RFC3339)
+
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/pastes":
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{
+				{Id: "old", Size: 1000, CreatedAt: ago(100 * 24 * time.Hour), DeleteURL: "https://0x45.st/delete/del-old"},
+				{Id: "new", Size: 2000, CreatedAt: ago(24 * time.Hour)},
+				{Id: "expired", Size: 500, CreatedAt: ago(24 * time.Hour), ExpiresAt: &past},
+				{Id: "live", Size: 700, CreatedAt: ago(24 * time.Hour), ExpiresAt: &future},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case strings.HasPrefix(r.URL.Path, "/delete/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/delete/"))
+			_ = json.NewEncoder(w).Encode(api.GenericResponse{Success: true, Message: "deleted"})
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	cmd := NewPruneCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	_ = cmd.Flags().Set("older-than", "90d")
+	_ = cmd.Flags().Set("expired", "true")
+	_ = cmd.Flags().Set("dry-run", "true")
+	if err := Prune(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 || !strings.Contains(buf.String(), "Would delete 2 pastes, freeing 1.5 kB") {
+		t.Errorf("Expected a preview of 2 pastes and nothing deleted, got %v and %s", deleted, buf.String())
+	}
+
+	buf.Reset()
+	_ = cmd.Flags().Set("dry-run", "false")
+	_ = cmd.Flags().Set("yes", "true")
+	if err := Prune(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(deleted)
+	if strings.Join(deleted, ",") != "del-old,expired" {
+		t.Errorf("Expected old and expired to be deleted by their delete IDs, got %v", deleted)
+	}
+	if !strings.Contains(buf.String(), "Deleted 2 of 2 pastes, freeing 1.5 kB") {
+		t.Errorf("Expected a summary, got %s", buf.String())
+	}
+
+	// Without --yes, the user is asked first.
+	deleted = nil
+	oldCanPrompt := canPrompt
+	canPrompt = func(*cobra.Command) bool { return true }
+	defer func() { canPrompt = oldCanPrompt }()
+	cmd = NewPruneCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader("n\n"))
+	_ = cmd.Flags().Set("expired", "true")
+	if err := Prune(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("Expected nothing to be deleted after declining, got %v", deleted)
+	}
+
+	var usageErr *UsageError
+	if err := Prune(NewPruneCmd(), nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error without criteria, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewQRCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "qr [url-or-id]",
+		Short: "Render a QR code for a URL or paste ID",
+		Args:  cobra.ExactArgs(1),
+		RunE:  QR,
+	}
+
+	return cmd
+}
+
+func QR(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+
+	target := args[0]
+	if u, err := url.Parse(target); err != nil || u.Scheme == "" {
+		target = strings.TrimRight(viper.GetString("api_url"), "/") + "/" + url.PathEscape(target)
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, map[string]string{"url": target})
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), target)
+	return printQR(cmd, target)
+}
+
+// printQR renders content as a QR code using Unicode half blocks.
+func printQR(cmd *cobra.Command, content string) error {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("error generating QR code: %w", err)
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), code.ToSmallString(false))
+	return nil
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// rateLimiter paces reads to a number of bytes per second. One limiter can
+// be shared by several readers, which then share the rate between them.
+type rateLimiter struct {
+	rate int64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// sleep is swapped out in tests.
+var sleep = time.Sleep
+
+// chunk is the most a single read may return, so the pauses between reads
+// stay short and the rate is smooth.
+func (l *rateLimiter) chunk() int {
+	return int(max(l.rate/10, 1))
+}
+
+// wait blocks until n more bytes may be sent. Unused time isn't saved up,
+// so a pause in sending doesn't allow a burst afterwards.
+func (l *rateLimiter) wait(n int) {
+	if n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+	sleep(delay)
+}
+
+// reader wraps r so it is read no faster than the limit. A nil limiter
+// returns r unchanged.
+func (l *rateLimiter) reader(r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{r: r, l: l}
+}
+
+type limitedReader struct {
+	r io.Reader
+	l *rateLimiter
+}
+
+func (lr *limitedReader) Read(b []byte) (int, error) {
+	if len(b) > lr.l.chunk() {
+		b = b[:lr.l.chunk()]
+	}
+	n, err := lr.r.Read(b)
+	lr.l.wait(n)
+	return n, err
+}
+
+// limitRateFlag returns a limiter for --limit-rate, or nil without one.
+func limitRateFlag(cmd *cobra.Command) (*rateLimiter, error) {
+	value, err := cmd.Flags().GetString("limit-rate")
+	if err != nil || value == "" {
+		return nil, err
+	}
+	rate, err := parseRate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &rateLimiter{rate: rate}, nil
+}
+
+// parseRate parses a rate in bytes per second such as 500k or 1.5M. As with
+// curl, the k, m and g suffixes are powers of 1024, and a trailing b is
+// allowed.
+func parseRate(value string) (int64, error) {
+	s := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "b")
+	multiplier := 1.0
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k':
+			multiplier = 1 << 10
+		case 'm':
+			multiplier = 1 << 20
+		case 'g':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(n, 0) || !(n*multiplier >= 1) {
+		return 0, usageErrorf("invalid rate %q: use bytes per second, optionally with a k, m or g suffix (e.g. 500k)", value)
+	}
+	return int64(n * multiplier), nil
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"1000", 1000},
+		{"500k", 500 << 10},
+		{"500K", 500 << 10},
+		{"1.5m", 3 << 19},
+		{"2MB", 2 << 20},
+		{"1g", 1 << 30},
+	}
+	for _, tt := range tests {
+		got, err := parseRate(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseRate(%q) = %d, %v; want %d", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "k", "fast", "0", "-5k", "0.5", "inf", "NaN"} {
+		var usageErr *UsageError
+		if _, err := parseRate(value); !errors.As(err, &usageErr) {
+			t.Errorf("parseRate(%q): expected a usage error, got %v", value, err)
+		}
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	oldSleep := sleep
+	sleep = func(time.Duration) {}
+	defer func() { sleep = oldSleep }()
+
+	limiter := &rateLimiter{rate: 1000}
+	data := strings.Repeat("x", 5000)
+	var out bytes.Buffer
+	buf := make([]byte, 4096)
+	start := time.Now()
+	n, err := io.CopyBuffer(&out, limiter.reader(strings.NewReader(data)), buf)
+	if err != nil || n != 5000 {
+		t.Fatalf("Expected all 5000 bytes, got %d, %v", n, err)
+	}
+
+	// 5000 bytes at 1000 bytes per second are paced over five seconds.
+	if paced := limiter.next.Sub(start); paced < 5*time.Second || paced > 6*time.Second {
+		t.Errorf("Expected about 5s of pacing, got %v", paced)
+	}
+
+	var nilLimiter *rateLimiter
+	r := strings.NewReader(data)
+	if nilLimiter.reader(r) != r {
+		t.Error("Expected no limit to leave the reader alone")
+	}
+}
+
+func TestUploadHandlerLimitRateOutlastsTimeout(t *testing.T) {
+	var stored []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stored, _ = io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	viper.Set("request_timeout", "100ms")
+	defer viper.Set("request_timeout", "")
+	client.Initialize()
+	defer client.Initialize()
+
+	// 40 bytes at 100 bytes per second take about 400ms, well past the
+	// timeout, but the connection never stalls.
+	content := strings.Repeat("x", 40)
+	cmd := NewUploadCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	_ = cmd.Flags().Set("content", content)
+	_ = cmd.Flags().Set("limit-rate", "100")
+	start := time.Now()
+	if err := Upload(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Expected the upload to be paced past the timeout, took %v", elapsed)
+	}
+	if string(stored) != content {
+		t.Errorf("Expected the whole content to arrive, got %q", stored)
+	}
+}
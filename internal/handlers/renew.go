@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func NewRenewCmd() *cobra.Command {
+	var expires string
+	var expiresAt string
+
+	cmd := &cobra.Command{
+		Use:   "renew [id]",
+		Short: "Update the expiration of a shortened URL",
+		Args:  cobra.ExactArgs(1),
+		RunE:  Renew,
+	}
+
+	cmd.Flags().StringVar(&expires, "expires", "", "New expiration time (e.g. 24h, 7d, 2w, or none)")
+	cmd.Flags().StringVar(&expiresAt, "expires-at", "", "New absolute expiration time (YYYY-MM-DD or RFC3339)")
+	cmd.MarkFlagsMutuallyExclusive("expires", "expires-at")
+
+	return cmd
+}
+
+func Renew(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+
+	expires, err := resolveExpiry(cmd)
+	if err != nil {
+		return err
+	}
+
+	if expires == "" {
+		return usageErrorf("the --expires or --expires-at flag is required (e.g. --expires 30d)")
+	}
+
+	resp, err := client.UpdateURLExpiration(args[0], expires)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			return notFoundErrorf("URL not found: %s", args[0])
+		}
+		return wrapAPIError("error renewing URL", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("error renewing URL: %s", resp.Error)
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, resp)
+	}
+
+	expiresAt := "never"
+	if resp.ExpiresAt != nil {
+		expiresAt = *resp.ExpiresAt
+		if t, err := time.Parse(time.RFC3339, *resp.ExpiresAt); err == nil {
+			expiresAt = formatTime(t, "2006-01-02")
+		}
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), theme.FormatSuccess("URL renewed"))
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("Short URL:"), theme.FormatURL(resp.ShortURL))
+	fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Expires", expiresAt))
+
+	return nil
+}
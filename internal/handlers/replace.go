@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// readTracker notes whether anything has been read from an upload body.
+type readTracker struct {
+	r    io.Reader
+	read bool
+}
+
+func (t *readTracker) Read(b []byte) (int, error) {
+	t.read = true
+	return t.r.Read(b)
+}
+
+// uploadRequest returns the method and endpoint of an upload, for --dry-run.
+func uploadRequest(replace string) (method, endpoint string) {
+	if replace != "" {
+		return "PUT", client.BaseURL() + "/" + replace
+	}
+	return "POST", client.BaseURL() + "/upload"
+}
+
+// sendUpload uploads body as a new paste or, with replace set, as the new
+// content of that paste so its URL stays the same. Servers that can't
+// replace pastes get a new upload instead, after which the old paste is
+// deleted; the new one is uploaded first so a failure doesn't lose both,
+// and if the old one can't be deleted that is an error naming it.
+func sendUpload(replace string, body io.Reader, size int64, opts api.UploadOptions) (*api.UploadResponse, error) {
+	if replace == "" {
+		return client.UploadReader(body, size, opts)
+	}
+
+	logging.Infof("Replacing paste %s", replace)
+	tracked := &readTracker{r: body}
+	resp, err := client.ReplacePaste(replace, tracked, size, opts)
+	if errors.Is(err, api.ErrNotFound) {
+		// Servers without the endpoint may answer 404 too, so that is only
+		// believed if the paste really is gone.
+		if exists, existsErr := client.Exists(replace, false); existsErr != nil || !exists {
+			return nil, notFoundErrorf("paste not found: %s", replace)
+		}
+		err = fmt.Errorf("%w (404 Not Found)", api.ErrReplaceUnsupported)
+	}
+	if !errors.Is(err, api.ErrReplaceUnsupported) {
+		return resp, err
+	}
+	if tracked.read {
+		return nil, fmt.Errorf("%w, and the content can't be sent again: upload it without --replace", err)
+	}
+
+	// The old paste may only be deletable by the ID in its delete URL, so
+	// that is found before anything new is uploaded.
+	deleteID, lookupErr := findPasteDeleteID(replace)
+	if lookupErr != nil {
+		return nil, fmt.Errorf("%w, and the old paste can't be deleted instead: %w", err, lookupErr)
+	}
+
+	logging.Warnf("%v; uploading a new paste and deleting %s instead, so the URL will change", err, replace)
+	resp, err = client.UploadReader(body, size, opts)
+	if err != nil || !resp.Success {
+		return resp, err
+	}
+	deleted, err := client.Delete(deleteID)
+	if err == nil && !deleted.Success {
+		err = errors.New(deleted.Error)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("uploaded the new content as %s, but could not delete the old paste %s, which is still online: %w", resp.URL, replace, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerReplace(t *testing.T) {
+	canReplace, canDelete := true, true
+	var requests []string
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/abc123":
+			if !canReplace {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			uploaded = string(data)
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+		case r.Method == http.MethodPost && r.URL.Path == "/upload":
+			data, _ := io.ReadAll(r.Body)
+			uploaded = string(data)
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/xyz789"})
+		case r.Method == http.MethodHead && r.URL.Path == "/other1":
+			// Someone else's paste, which isn't in the list.
+		case r.Method == http.MethodGet && r.URL.Path == "/pastes":
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{{Id: "abc123", DeleteURL: "https://0x45.st/delete/del456"}}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == http.MethodDelete && r.URL.Path == "/delete/del456":
+			if !canDelete {
+				_ = json.NewEncoder(w).Encode(api.GenericResponse{Success: false, Error: "forbidden"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(api.GenericResponse{Success: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("new content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := func(replace string) (string, error) {
+		cmd := NewUploadCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		_ = cmd.Flags().Set("replace", replace)
+		err := Upload(cmd, []string{file})
+		return buf.String(), err
+	}
+
+	output, err := upload("https://0x45.st/abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != "new content" || !strings.Contains(output, "https://0x45.st/abc123") || len(requests) != 1 {
+		t.Errorf("Expected the paste to be replaced in place, got %v and %q: %s", requests, uploaded, output)
+	}
+
+	canReplace, requests, uploaded = false, nil, ""
+	output, err = upload("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"PUT /abc123", "GET /pastes", "POST /upload", "DELETE /delete/del456"}
+	if strings.Join(requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected a new upload and then a delete by the delete URL's ID, got %v", requests)
+	}
+	if uploaded != "new content" || !strings.Contains(output, "https://0x45.st/xyz789") {
+		t.Errorf("Expected the new URL, got %q: %s", uploaded, output)
+	}
+
+	// Failing to delete the old paste leaves two, which is an error.
+	canDelete = false
+	_, err = upload("abc123")
+	if err == nil || !strings.Contains(err.Error(), "old paste abc123") || !strings.Contains(err.Error(), "https://0x45.st/xyz789") {
+		t.Errorf("Expected an error naming both pastes, got %v", err)
+	}
+	canDelete = true
+
+	// Pastes that aren't in your list can't be deleted, so nothing new is
+	// uploaded for them.
+	requests = nil
+	_, err = upload("other1")
+	if !errors.Is(err, api.ErrReplaceUnsupported) || !strings.Contains(err.Error(), "isn't one of your pastes") || slices.Contains(requests, "POST /upload") {
+		t.Errorf("Expected an unknown paste to be refused before uploading, got %v after %v", err, requests)
+	}
+
+	if _, err := upload("missing"); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("Expected a missing paste to be not found, got %v", err)
+	}
+
+	cmd := NewUploadCmd()
+	_ = cmd.Flags().Set("replace", "abc123")
+	_ = cmd.Flags().Set("dedupe", "true")
+	var usageErr *UsageError
+	if err := Upload(cmd, []string{file}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected --replace with --dedupe to be a usage error, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// defaultChunkSize is how much of a --resumable upload is sent per request.
+const defaultChunkSize = 8 * humanize.MiByte
+
+// resumableState is what a --resumable upload keeps on disk while it runs,
+// so an interrupted upload can carry on where the server left off.
+type resumableState struct {
+	UploadID  string `json:"upload_id"`
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size"`
+	Offset    int64  `json:"offset"`
+	StartedAt string `json:"started_at"`
+	// Flags holds the resumableFlags the upload was started with. It is nil
+	// for uploads started before they were recorded, which aren't checked.
+	Flags map[string]string `json:"flags"`
+}
+
+// resumableFlags are the upload flags whose options the server takes when
+// an upload starts, so a resumed upload can't change them.
+var resumableFlags = []string{"filename", "private", "expires", "expires-at", "mime", "lang", "title", "password"}
+
+// startFlags returns the resumableFlags set on cmd, as given. Passwords are
+// only recorded as being set, so they never reach the disk.
+func startFlags(cmd *cobra.Command) map[string]string {
+	flags := map[string]string{}
+	for _, name := range resumableFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		flags[name] = flag.Value.String()
+		if name == "password" {
+			flags[name] = "set"
+		}
+	}
+	return flags
+}
+
+// changedFlags lists the resumableFlags that differ between two uploads.
+func changedFlags(started, now map[string]string) []string {
+	var changed []string
+	for _, name := range resumableFlags {
+		before, wasSet := started[name]
+		after, isSet := now[name]
+		if wasSet != isSet || before != after {
+			changed = append(changed, "--"+name)
+		}
+	}
+	return changed
+}
+
+// cacheDir returns the directory for data that can be thrown away,
+// honouring XDG_CACHE_HOME.
+func cacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "0x45"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "0x45"), nil
+}
+
+// resumableStatePath is where the progress of a --resumable upload of the
+// content with the given hash is kept.
+func resumableStatePath(hash string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "uploads", hash+".json"), nil
+}
+
+// loadResumableState reads the state at statePath, returning nil if there
+// is none.
+func loadResumableState(statePath string) (*resumableState, error) {
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading upload state: %w", err)
+	}
+	var state resumableState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error reading upload state: %w", err)
+	}
+	return &state, nil
+}
+
+func saveResumableState(statePath string, state *resumableState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding upload state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return fmt.Errorf("error creating upload state directory: %w", err)
+	}
+	if err := os.WriteFile(statePath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("error writing upload state: %w", err)
+	}
+	return nil
+}
+
+// chunkSizeFlag returns the --chunk-size value in bytes.
+func chunkSizeFlag(cmd *cobra.Command) (int64, error) {
+	value, err := cmd.Flags().GetString("chunk-size")
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return defaultChunkSize, nil
+	}
+	size, err := parseSize(value)
+	if err != nil {
+		return 0, usageErrorf("invalid chunk size %q: use a number of bytes, optionally with a unit (e.g. 4MB)", value)
+	}
+	return size, nil
+}
+
+// uploadResumable uploads file in chunks of chunkSize. Progress is recorded
+// under the cache directory, keyed by the content's hash, so running the
+// same upload again after an interruption resumes from the last chunk the
+// server acknowledged. Servers without chunked uploads yield an error
+// matching api.ErrChunkedUnsupported, leaving the caller to fall back to a
+// regular upload.
+func uploadResumable(cmd *cobra.Command, file *os.File, size int64, hash string, chunkSize int64, opts api.UploadOptions, limiter *rateLimiter, showProgress bool) (*api.UploadResponse, error) {
+	statePath, err := resumableStatePath(hash)
+	if err != nil {
+		return nil, err
+	}
+	state, err := loadResumableState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var offset int64
+	if state != nil && state.Size != size {
+		state = nil
+	}
+	flags := startFlags(cmd)
+	if state != nil && state.Flags != nil {
+		if changed := changedFlags(state.Flags, flags); len(changed) > 0 {
+			return nil, usageErrorf("upload %s was started with different %s; run it with the same options to resume, or remove %s to start over",
+				state.UploadID, strings.Join(changed, ", "), statePath)
+		}
+	}
+	if state != nil {
+		status, err := client.ChunkedUploadStatus(state.UploadID)
+		switch {
+		case errors.Is(err, api.ErrNotFound):
+			logging.Infof("Upload %s is no longer on the server, starting over", state.UploadID)
+			state = nil
+		case err != nil:
+			return nil, wrapAPIError("error checking interrupted upload", err)
+		case status.Offset < 0 || status.Offset > size:
+			return nil, fmt.Errorf("error checking interrupted upload: server reported offset %d of %d bytes", status.Offset, size)
+		default:
+			offset = status.Offset
+			if !jsonOutput() && !quietOutput() {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Resuming upload at %s of %s\n", humanize.Bytes(uint64(offset)), humanize.Bytes(uint64(size)))
+			}
+		}
+	}
+
+	if state == nil {
+		resp, err := client.StartChunkedUpload(size, opts)
+		if errors.Is(err, api.ErrChunkedUnsupported) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, wrapAPIError("error starting upload", err)
+		}
+		if !resp.Success {
+			return nil, fmt.Errorf("error starting upload: %s", resp.Error)
+		}
+		state = &resumableState{
+			UploadID:  resp.UploadID,
+			Filename:  opts.Filename,
+			Size:      size,
+			StartedAt: time.Now().Format(time.RFC3339),
+			Flags:     flags,
+		}
+		// The upload can still go ahead, it just can't be resumed.
+		if err := saveResumableState(statePath, state); err != nil {
+			logging.Warnf("Could not save upload progress: %v", err)
+		}
+	}
+
+	var progress *progressReader
+	if showProgress && size > 0 {
+		progress = newProgressReader(nil, size, cmd.ErrOrStderr())
+		progress.add(int(offset))
+		defer progress.Finish()
+	}
+
+	interrupted := func(err error) error {
+		return wrapAPIError(fmt.Sprintf("upload interrupted at %s of %s (run the same command again to resume)",
+			humanize.Bytes(uint64(offset)), humanize.Bytes(uint64(size))), err)
+	}
+
+	chunk := make([]byte, min(chunkSize, size))
+	for offset < size {
+		n := min(chunkSize, size-offset)
+		// Reading through the limiter paces the chunks to --limit-rate.
+		if _, err := io.ReadFull(limiter.reader(io.NewSectionReader(file, offset, n)), chunk[:n]); err != nil {
+			return nil, fmt.Errorf("error reading file: %w", err)
+		}
+
+		logging.Debugf("Sending bytes %d-%d of %d", offset, offset+n-1, size)
+		resp, err := client.UploadChunk(state.UploadID, offset, chunk[:n])
+		if err != nil {
+			return nil, interrupted(err)
+		}
+		if !resp.Success {
+			return nil, interrupted(errors.New(resp.Error))
+		}
+		if resp.Offset <= offset || resp.Offset > size {
+			return nil, fmt.Errorf("error uploading chunk: server acknowledged offset %d after a chunk at %d", resp.Offset, offset)
+		}
+
+		if progress != nil {
+			progress.add(int(resp.Offset - offset))
+		}
+		offset = resp.Offset
+		state.Offset = offset
+		if err := saveResumableState(statePath, state); err != nil {
+			logging.Warnf("Could not save upload progress: %v", err)
+		}
+	}
+
+	resp, err := client.CompleteChunkedUpload(state.UploadID)
+	if err != nil {
+		return nil, interrupted(err)
+	}
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		logging.Warnf("Could not remove upload state: %v", err)
+	}
+	return resp, nil
+}
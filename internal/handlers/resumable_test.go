@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestUploadHandlerResumable(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var stored []byte
+	starts, failAt := 0, int64(4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/uploads":
+			starts++
+			stored = nil
+			if r.Header.Get("Upload-Length") != "10" || r.Header.Get("X-Filename") != "data.bin" {
+				t.Errorf("Unexpected upload headers: %v", r.Header)
+			}
+			_ = json.NewEncoder(w).Encode(api.ChunkedUploadResponse{Success: true, UploadID: "up1"})
+		case r.Method == http.MethodGet && r.URL.Path == "/uploads/up1":
+			_ = json.NewEncoder(w).Encode(api.ChunkedUploadResponse{Success: true, UploadID: "up1", Offset: int64(len(stored))})
+		case r.Method == http.MethodPatch && r.URL.Path == "/uploads/up1":
+			offset, _ := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+			if offset == failAt {
+				failAt = -1
+				http.Error(w, "connection reset", http.StatusBadGateway)
+				return
+			}
+			if offset != int64(len(stored)) {
+				t.Errorf("Expected a chunk at %d, got one at %d", len(stored), offset)
+			}
+			data, _ := io.ReadAll(r.Body)
+			stored = append(stored, data...)
+			_ = json.NewEncoder(w).Encode(api.ChunkedUploadResponse{Success: true, Offset: int64(len(stored))})
+		case r.Method == http.MethodPost && r.URL.Path == "/uploads/up1/complete":
+			_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	file := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(file, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath, err := resumableStatePath(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload := func(flags ...string) (string, error) {
+		cmd := NewUploadCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&buf)
+		_ = cmd.Flags().Set("resumable", "true")
+		_ = cmd.Flags().Set("chunk-size", "4")
+		for i := 0; i < len(flags); i += 2 {
+			_ = cmd.Flags().Set(flags[i], flags[i+1])
+		}
+		err := Upload(cmd, []string{file})
+		return buf.String(), err
+	}
+
+	if _, err := upload("title", "Backup", "password", "hunter2"); err == nil || !strings.Contains(err.Error(), "upload interrupted at 4 B of 10 B") {
+		t.Fatalf("Expected the upload to be interrupted, got %v", err)
+	}
+	state, err := loadResumableState(statePath)
+	if err != nil || state == nil || state.UploadID != "up1" || state.Offset != 4 {
+		t.Fatalf("Expected the progress to be saved, got %+v (%v)", state, err)
+	}
+	if data, _ := os.ReadFile(statePath); bytes.Contains(data, []byte("hunter2")) {
+		t.Errorf("Expected the password to be kept out of the saved progress, got %s", data)
+	}
+
+	// The server already has the options the upload started with, so
+	// different ones are refused rather than silently ignored.
+	var usageErr *UsageError
+	_, err = upload("title", "Other", "password", "hunter2", "private", "true")
+	if !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "different --private, --title") {
+		t.Errorf("Expected changed options to be a usage error, got %v", err)
+	}
+	if _, err := upload(); !errors.As(err, &usageErr) || !strings.Contains(err.Error(), "--title, --password") {
+		t.Errorf("Expected dropped options to be a usage error, got %v", err)
+	}
+	if starts != 1 || len(stored) != 4 {
+		t.Errorf("Expected nothing to be sent with different options, got %d starts and %q", starts, stored)
+	}
+
+	output, err := upload("title", "Backup", "password", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if starts != 1 {
+		t.Errorf("Expected the upload to be resumed, got %d starts", starts)
+	}
+	if string(stored) != "0123456789" {
+		t.Errorf("Expected the whole file to be stored, got %q", stored)
+	}
+	if !strings.Contains(output, "Resuming upload at 4 B of 10 B") || !strings.Contains(output, "https://0x45.st/abc123") {
+		t.Errorf("Unexpected output: %s", output)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("Expected the progress to be removed after the upload, got %v", err)
+	}
+}
+
+func TestUploadHandlerResumableUnsupported(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/upload" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		uploaded = string(data)
+		_ = json.NewEncoder(w).Encode(api.UploadResponse{Success: true, URL: "https://0x45.st/abc123"})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	file := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(file, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewUploadCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	_ = cmd.Flags().Set("resumable", "true")
+	if err := Upload(cmd, []string{file}); err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != "0123456789" || !strings.Contains(buf.String(), "https://0x45.st/abc123") {
+		t.Errorf("Expected a regular upload, got %q: %s", uploaded, buf.String())
+	}
+
+	for _, tt := range []struct {
+		flags map[string]string
+		args  []string
+	}{
+		{map[string]string{"resumable": "true", "content": "text"}, nil},
+		{map[string]string{"resumable": "true", "encrypt": "true"}, []string{file}},
+		{map[string]string{"chunk-size": "4MB"}, []string{file}},
+		{map[string]string{"resumable": "true", "chunk-size": "lots"}, []string{file}},
+	} {
+		cmd := NewUploadCmd()
+		for name, value := range tt.flags {
+			_ = cmd.Flags().Set(name, value)
+		}
+		var usageErr *UsageError
+		if err := Upload(cmd, tt.args); !errors.As(err, &usageErr) {
+			t.Errorf("Expected a usage error for %v, got %v", tt.flags, err)
+		}
+	}
+}
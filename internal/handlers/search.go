@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func NewSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search your pastes by filename or title",
+		Long: `Search every page of your pastes for filenames or titles containing the
+query, ignoring case. With --urls, shortened URLs are searched by their target too.`,
+		Args: cobra.ExactArgs(1),
+		RunE: Search,
+	}
+
+	cmd.Flags().Bool("urls", false, "Also search shortened URLs")
+	cmd.Flags().Bool("regex", false, "Treat the query as a regular expression")
+	cmd.Flags().Int("limit", 0, "Show at most this many matches (0 for all)")
+
+	return cmd
+}
+
+func Search(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+	query := args[0]
+
+	searchURLs, err := cmd.Flags().GetBool("urls")
+	if err != nil {
+		return err
+	}
+
+	useRegex, err := cmd.Flags().GetBool("regex")
+	if err != nil {
+		return err
+	}
+
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return err
+	}
+	if limit < 0 {
+		return usageErrorf("invalid limit: %d", limit)
+	}
+
+	match, err := searchMatcher(query, useRegex)
+	if err != nil {
+		return err
+	}
+
+	pastes, err := fetchAll(client.ListPastes, api.ListOptions{})
+	if err != nil {
+		return wrapAPIError("error listing pastes", err)
+	}
+	matchedPastes := matchItems(pastes.Data.Items, match, func(item api.PasteListItem) []string {
+		return []string{item.Filename, item.Title}
+	})
+
+	var matchedURLs []api.URLListItem
+	if searchURLs {
+		urls, err := fetchAll(client.ListURLs, api.ListOptions{})
+		if err != nil {
+			return wrapAPIError("error listing URLs", err)
+		}
+		matchedURLs = matchItems(urls.Data.Items, match, func(item api.URLListItem) []string {
+			return []string{item.OriginalURL}
+		})
+	}
+
+	entries := mergeEntries(matchedPastes, matchedURLs, false)
+	total := len(entries)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, entries)
+	}
+
+	out := cmd.OutOrStdout()
+	if total == 0 {
+		fmt.Fprintln(out, theme.FormatWarning(fmt.Sprintf("Nothing matches %q", query)))
+		return nil
+	}
+
+	fmt.Fprintln(out, theme.Title.Render("Search Results"))
+	if len(entries) < total {
+		fmt.Fprintln(out, theme.Subtitle.Render(fmt.Sprintf("Showing %d of %d matches for %q", len(entries), total, query)))
+	} else {
+		fmt.Fprintln(out, theme.Subtitle.Render(fmt.Sprintf("%d matches for %q", total, query)))
+	}
+	printEntries(out, entries, pasteURLOptions{})
+	return nil
+}
+
+// searchMatcher returns a case-insensitive matcher for query, either as a
+// substring or, with useRegex, as a regular expression.
+func searchMatcher(query string, useRegex bool) (func(string) bool, error) {
+	if !useRegex {
+		query = strings.ToLower(query)
+		return func(s string) bool {
+			return strings.Contains(strings.ToLower(s), query)
+		}, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + query)
+	if err != nil {
+		return nil, usageErrorf("invalid regular expression: %v", err)
+	}
+	return re.MatchString, nil
+}
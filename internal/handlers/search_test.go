@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestSearchHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pastes":
+			resp := api.ListResponse[api.PasteListItem]{Success: true}
+			resp.Data.Items = []api.PasteListItem{
+				{Id: "p1", Filename: "notes.txt", CreatedAt: "2024-01-01T00:00:00Z"},
+				{Id: "p2", Filename: "main.go", CreatedAt: "2024-01-02T00:00:00Z"},
+				{Id: "p3", Filename: "Meeting-Notes.md", CreatedAt: "2024-01-03T00:00:00Z"},
+				{Id: "p4", Filename: "todo.txt", Title: "Weekly Agenda", CreatedAt: "2023-12-01T00:00:00Z"},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/urls":
+			resp := api.ListResponse[api.URLListItem]{Success: true}
+			resp.Data.Items = []api.URLListItem{
+				{Id: "u1", OriginalURL: "https://example.com/notes", CreatedAt: "2024-01-04T00:00:00Z"},
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	search := func(query string, flags map[string]string) (string, error) {
+		cmd := NewSearchCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		for name, value := range flags {
+			_ = cmd.Flags().Set(name, value)
+		}
+		err := Search(cmd, []string{query})
+		return buf.String(), err
+	}
+
+	output, err := search("notes", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "p1") || !strings.Contains(output, "p3") || strings.Contains(output, "p2") || strings.Contains(output, "u1") {
+		t.Errorf("Expected only the notes pastes, got %s", output)
+	}
+
+	output, err = search("notes", map[string]string{"urls": "true", "limit": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "u1") || !strings.Contains(output, "p3") || strings.Contains(output, "p1") {
+		t.Errorf("Expected the two newest matches including the URL, got %s", output)
+	}
+	if !strings.Contains(output, "Showing 2 of 3 matches") {
+		t.Errorf("Expected the limit to be reported, got %s", output)
+	}
+
+	output, err = search(`\.go$`, map[string]string{"regex": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "p2") || strings.Contains(output, "p1") {
+		t.Errorf("Expected only main.go to match, got %s", output)
+	}
+
+	output, err = search("agenda", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "p4") || strings.Contains(output, "p1") {
+		t.Errorf("Expected the paste to match by its title, got %s", output)
+	}
+
+	if _, err := search("(", map[string]string{"regex": "true"}); err == nil {
+		t.Error("Expected invalid regex to be rejected")
+	}
+}
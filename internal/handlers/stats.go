@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func NewStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats [id]",
+		Short: "Show click statistics for a shortened URL",
+		Long: `Show click statistics for a shortened URL.
+
+With --watch, the stats are polled every --interval until Ctrl-C, showing the
+click count and how many clicks came in since the last poll.`,
+		Example: "  0x45 stats abc123\n  0x45 stats abc123 --watch --interval 10s",
+		Args:    cobra.ExactArgs(1),
+		RunE:    Stats,
+	}
+
+	cmd.Flags().String("format", "", "Print the stats with a Go template (e.g. '{{.clicks}}')")
+	cmd.Flags().BoolP("watch", "w", false, "Keep polling and show new clicks as they come in, until Ctrl-C")
+	cmd.Flags().Duration("interval", defaultWatchInterval, "How often to poll with --watch")
+
+	return cmd
+}
+
+func Stats(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("expected 1 argument, got %d", len(args))
+	}
+
+	tmpl, err := formatFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	watch, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return err
+	}
+	if watch {
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		if interval < minWatchInterval {
+			return usageErrorf("invalid interval %s: must be at least %s", interval, minWatchInterval)
+		}
+		return watchStats(cmd, args[0], interval, tmpl)
+	}
+	if cmd.Flags().Changed("interval") {
+		return usageErrorf("--interval can only be used with --watch")
+	}
+
+	resp, err := getURLStats(args[0])
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(cmd, resp)
+	}
+	if tmpl != nil {
+		return printTemplate(cmd, tmpl, resp)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), theme.Title.Render("URL Stats"))
+	fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("ID", resp.Id))
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("Short URL:"), theme.FormatURL(resp.ShortURL))
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", theme.ListItemKey.Render("Original URL:"), theme.FormatURL(resp.OriginalURL))
+	fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Clicks", fmt.Sprintf("%d", resp.Clicks)))
+
+	if resp.LastClick == nil {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Last Click", "no clicks yet"))
+	} else {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Last Click", formatTimestamp(*resp.LastClick)))
+	}
+
+	if resp.ExpiresAt == nil {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Expires", "never"))
+	} else {
+		fmt.Fprintln(cmd.OutOrStdout(), theme.FormatKeyValue("Expires", formatTimestamp(*resp.ExpiresAt)))
+	}
+
+	return nil
+}
+
+// getURLStats fetches the stats of the short URL id.
+func getURLStats(id string) (*api.URLStatsResponse, error) {
+	resp, err := client.GetURLStats(id)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			return nil, notFoundErrorf("URL not found: %s", id)
+		}
+		return nil, wrapAPIError("error getting URL stats", err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("error getting URL stats: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// formatTimestamp normalizes an RFC3339 timestamp from the API, falling back
+// to the raw value if it can't be parsed.
+func formatTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return formatTime(t, time.RFC3339)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/h2non/filetype"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// defaultStdinFilename names stdin pastes when default_stdin_filename isn't
+// configured.
+const defaultStdinFilename = "paste.txt"
+
+// sniffLen is how much content is inspected to detect its type, matching
+// what http.DetectContentType considers.
+const sniffLen = 512
+
+// peekContent reads up to sniffLen bytes from r for type detection and
+// returns a reader that replays them ahead of the rest of r, so large piped
+// inputs are never buffered in full.
+func peekContent(r io.Reader) (io.Reader, []byte, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, err
+	}
+	head = head[:n]
+	return io.MultiReader(bytes.NewReader(head), r), head, nil
+}
+
+// detectContentType guesses an extension and MIME type from the first bytes
+// of some content. Unrecognized content is treated as plain text when it is
+// valid UTF-8, and as opaque binary otherwise; in both cases no MIME type is
+// returned so the server can make its own choice.
+func detectContentType(head []byte) (ext, mimeType string) {
+	if kind, err := filetype.Match(head); err == nil && kind != filetype.Unknown {
+		return kind.Extension, kind.MIME.Value
+	}
+	if utf8.Valid(trimPartialRune(head)) {
+		return "txt", ""
+	}
+	return "bin", ""
+}
+
+// stdinFilename names a stdin paste after the configured default. Text keeps
+// that name as is, while a detected binary type or an explicit --lang
+// replaces its extension, so a piped image is still uploaded as paste.png.
+func stdinFilename(configured, detectedExt, langExt string) string {
+	if configured == "" {
+		configured = defaultStdinFilename
+	}
+
+	ext := langExt
+	if ext == "" && detectedExt != "txt" {
+		ext = detectedExt
+	}
+	if ext == "" {
+		return configured
+	}
+	return strings.TrimSuffix(configured, fileExt(configured)) + "." + ext
+}
+
+// trimPartialRune drops a multi-byte character cut off at the end of head by
+// the sniffLen limit, so valid text isn't mistaken for binary.
+func trimPartialRune(head []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(head); i++ {
+		if utf8.RuneStart(head[len(head)-i]) {
+			if !utf8.FullRune(head[len(head)-i:]) {
+				return head[:len(head)-i]
+			}
+			break
+		}
+	}
+	return head
+}
+
+// stdinIsTerminal reports whether the command's input is an interactive
+// terminal rather than a pipe or file.
+func stdinIsTerminal(cmd *cobra.Command) bool {
+	f, ok := cmd.InOrStdin().(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/dustin/go-humanize"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+	"golang.org/x/term"
+)
+
+// minFlexWidth is the narrowest the flexible column is shrunk to.
+const minFlexWidth = 10
+
+// terminalWidth returns the width of stdout, or 0 if it isn't a terminal.
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// renderTable writes rows as aligned columns. When maxWidth is positive the
+// flex column is shrunk (and its cells truncated) to fit.
+func renderTable(w io.Writer, headers []string, rows [][]string, maxWidth, flex int) {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = ansi.StringWidth(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], ansi.StringWidth(cell))
+		}
+	}
+
+	if maxWidth > 0 {
+		// Every cell carries one column of padding on each side.
+		total := 2 * len(widths)
+		for _, width := range widths {
+			total += width
+		}
+		if total > maxWidth {
+			widths[flex] = max(minFlexWidth, widths[flex]-(total-maxWidth))
+		}
+	}
+
+	render := func(style lipgloss.Style, cells []string) string {
+		rendered := make([]string, len(cells))
+		for i, cell := range cells {
+			rendered[i] = style.Width(widths[i] + 2).Render(ansi.Truncate(cell, widths[i], "…"))
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	}
+
+	fmt.Fprintln(w, render(theme.TableHeader, headers))
+	for _, row := range rows {
+		fmt.Fprintln(w, render(theme.TableCell, row))
+	}
+}
+
+// formatTableTime shortens an RFC3339 timestamp for table cells.
+func formatTableTime(value *string) string {
+	if value == nil || *value == "" {
+		return "never"
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return *value
+	}
+	return formatTime(t, "2006-01-02 15:04")
+}
+
+func renderPasteTable(w io.Writer, items []api.PasteListItem) {
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []string{
+			item.Id,
+			item.Filename,
+			humanize.Bytes(uint64(item.Size)),
+			formatTableTime(&item.CreatedAt),
+			formatTableTime(item.ExpiresAt),
+		})
+	}
+	renderTable(w, []string{"ID", "Filename", "Size", "Created", "Expires"}, rows, terminalWidth(), 1)
+}
+
+func renderURLTable(w io.Writer, items []api.URLListItem) {
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []string{
+			item.Id,
+			item.OriginalURL,
+			fmt.Sprintf("%d", item.Clicks),
+			formatTableTime(&item.CreatedAt),
+			formatTableTime(item.ExpiresAt),
+		})
+	}
+	renderTable(w, []string{"ID", "URL", "Clicks", "Created", "Expires"}, rows, terminalWidth(), 1)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/viper"
+)
+
+func TestRenderTable(t *testing.T) {
+	headers := []string{"ID", "URL", "Clicks"}
+	rows := [][]string{
+		{"abc123", "https://example.com/a/very/long/path/that/will/not/fit", "42"},
+		{"def456", "https://example.com", "7"},
+	}
+
+	var buf bytes.Buffer
+	renderTable(&buf, headers, rows, 0, 1)
+	if !strings.Contains(buf.String(), rows[0][1]) {
+		t.Errorf("Expected full URL without a width limit, got %s", buf.String())
+	}
+
+	buf.Reset()
+	renderTable(&buf, headers, rows, 40, 1)
+	output := buf.String()
+	if strings.Contains(output, rows[0][1]) {
+		t.Error("Expected long URL to be truncated")
+	}
+	if !strings.Contains(output, "…") {
+		t.Error("Expected truncated URL to end with an ellipsis")
+	}
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if width := ansi.StringWidth(line); width > 40 {
+			t.Errorf("Expected lines to fit in 40 columns, got %d: %q", width, line)
+		}
+	}
+}
+
+func TestFormatTableTime(t *testing.T) {
+	defer viper.Set("date_format", "")
+
+	value := "2024-03-05T14:30:00Z"
+	tests := map[string]string{
+		"":                          "2024-03-05 14:30",
+		"Jan 2, 2006":               "Mar 5, 2024",
+		"2006-01-02T15:04:05Z07:00": value,
+	}
+	for format, want := range tests {
+		viper.Set("date_format", format)
+		if got := formatTableTime(&value); got != want {
+			t.Errorf("date_format %q: got %q, want %q", format, got, want)
+		}
+	}
+
+	viper.Set("date_format", "")
+	viper.Set("relative", true)
+	defer viper.Set("relative", false)
+	recent := time.Now().Add(-72 * time.Hour).Format(time.RFC3339)
+	if got := formatTableTime(&recent); got != "3 days ago" {
+		t.Errorf("Expected a relative time, got %q", got)
+	}
+	if got := formatTableTime(nil); got != "never" {
+		t.Errorf("Expected never for a missing time, got %q", got)
+	}
+}
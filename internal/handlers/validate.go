@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	"github.com/watzon/0x45-cli/internal/logging"
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+// configIssue is a problem found by config validate. Fatal problems would
+// stop commands from working; the rest are likely mistakes.
+type configIssue struct {
+	Key     string `json:"key"`
+	Fatal   bool   `json:"fatal"`
+	Problem string `json:"problem"`
+	Fix     string `json:"fix"`
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config for common problems",
+		Long: `Check the loaded config for common problems, such as a missing API key,
+a malformed api_url or a misspelled key, and suggest a fix for each.
+
+Exits non-zero if any problem would stop commands from working.`,
+		Args: cobra.NoArgs,
+		RunE: ConfigValidate,
+	}
+}
+
+func ConfigValidate(cmd *cobra.Command, args []string) error {
+	issues := validateConfig(viper.ConfigFileUsed())
+
+	fatal := 0
+	for _, issue := range issues {
+		if issue.Fatal {
+			fatal++
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput() {
+		if issues == nil {
+			issues = []configIssue{}
+		}
+		if err := printJSON(cmd, issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			msg := fmt.Sprintf("%s: %s", issue.Key, issue.Problem)
+			if issue.Fatal {
+				fmt.Fprintln(out, theme.FormatError(msg))
+			} else {
+				fmt.Fprintln(out, theme.FormatWarning(msg))
+			}
+			fmt.Fprintln(out, "  Fix:", issue.Fix)
+		}
+		if len(issues) == 0 {
+			fmt.Fprintln(out, theme.FormatSuccess("No problems found"))
+		}
+	}
+
+	if fatal > 0 {
+		return fmt.Errorf("found %d problem(s) in the config", fatal)
+	}
+	return nil
+}
+
+// validateConfig checks the loaded config, and the keys in the config file
+// at path if there is one.
+func validateConfig(path string) []configIssue {
+	var issues []configIssue
+	add := func(key string, fatal bool, fix, format string, args ...any) {
+		issues = append(issues, configIssue{Key: key, Fatal: fatal, Problem: fmt.Sprintf(format, args...), Fix: fix})
+	}
+
+	if raw := viper.GetString("api_key"); strings.TrimSpace(raw) == "" {
+		add("api_key", true, "run '0x45 config set api_key --stdin' and enter your key", "not set")
+	} else if key, err := client.ResolveAPIKey(raw); err != nil {
+		add("api_key", true, "check the file: or env: reference, or set the key itself", "%v", err)
+	} else if key == "" {
+		add("api_key", true, "put your key in the referenced file or variable", "resolves to an empty key")
+	}
+
+	raw := viper.GetString("api_url")
+	if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("api_url", true, "use a full URL, e.g. '0x45 config set api_url https://0x45.st'", "invalid URL %q", raw)
+	}
+
+	if raw := viper.GetString("default_expiry"); raw != "" && !isNoExpiry(raw) {
+		if _, err := parseExpiry(raw); err != nil {
+			add("default_expiry", true, "use a duration such as 24h, 7d or 2w, or never", "invalid duration %q", raw)
+		}
+	}
+
+	if raw := viper.GetString("request_timeout"); raw != "" {
+		if _, err := time.ParseDuration(raw); err != nil {
+			add("request_timeout", true, "use a duration such as 30s or 2m", "invalid duration %q", raw)
+		}
+	}
+
+	if raw := viper.GetString("max_upload_size"); raw != "" {
+		if _, err := parseSize(raw); err != nil {
+			add("max_upload_size", true, "use a number of bytes, optionally with a unit, e.g. 10MB", "invalid size %q", raw)
+		}
+	}
+
+	for _, key := range []string{"max_expiry_days", "max_expiry_days_anon"} {
+		if raw := viper.GetString(key); raw != "" {
+			if n, err := strconv.Atoi(raw); err != nil || n <= 0 {
+				add(key, true, "use a whole number of days, e.g. 365", "invalid number of days %q", raw)
+			}
+		}
+	}
+
+	if raw := viper.GetString("log_level"); raw != "" {
+		if _, err := logging.ParseLevel(raw); err != nil {
+			add("log_level", false, "use debug, info, warn or error", "%v", err)
+		}
+	}
+
+	if raw := viper.GetString("filename_via"); raw != "" && !slices.Contains([]string{"both", "header", "query"}, strings.ToLower(raw)) {
+		add("filename_via", true, "use header, query or both", "invalid value %q", raw)
+	}
+
+	if path == "" {
+		return issues
+	}
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		add(path, true, "fix the file with '0x45 config edit'", "could not read config file: %v", err)
+		return issues
+	}
+	for _, key := range file.AllKeys() {
+		name := configKeyName(key)
+		if name == "" || slices.Contains(ConfigKeys, name) {
+			continue
+		}
+		fix := "remove it, or check the spelling"
+		if suggestion := suggestConfigKey(name); suggestion != "" {
+			fix = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+		add(key, false, fix, "unknown key")
+	}
+	return issues
+}
+
+// configKeyName returns the setting a flattened config file key refers to,
+// looking inside profiles, or "" for keys whose contents aren't settings,
+// such as theme colors.
+func configKeyName(key string) string {
+	parts := strings.Split(key, ".")
+	switch {
+	case parts[0] == "theme":
+		return ""
+	case parts[0] == "profiles" && len(parts) > 2:
+		return parts[2]
+	}
+	return parts[0]
+}
+
+// suggestConfigKey returns the known key closest to name, if it is close
+// enough to be a typo.
+func suggestConfigKey(name string) string {
+	best, bestDist := "", 3
+	for _, known := range ConfigKeys {
+		if d := editDistance(name, known); d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
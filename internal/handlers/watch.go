@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/watzon/0x45-cli/internal/logging"
+	"github.com/watzon/0x45-cli/internal/theme"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+// Polling intervals for stats --watch. The minimum keeps a typo from
+// hammering the server.
+const (
+	defaultWatchInterval = 5 * time.Second
+	minWatchInterval     = time.Second
+)
+
+// after is swapped out in tests.
+var after = time.After
+
+// statsSnapshot is one poll of stats --watch. Delta is the number of clicks
+// since the previous poll.
+type statsSnapshot struct {
+	Time  string `json:"time"`
+	Delta int64  `json:"delta"`
+	*api.URLStatsResponse
+}
+
+// watchStats polls the stats of id every interval until the command's
+// context is cancelled by Ctrl-C. On a terminal the click count is redrawn
+// in place; otherwise, and with --json, --format or --quiet, each poll is
+// printed on a line of its own.
+func watchStats(cmd *cobra.Command, id string, interval time.Duration, tmpl *template.Template) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out := cmd.OutOrStdout()
+	inPlace := !jsonOutput() && !quietOutput() && tmpl == nil && isTerminal()
+
+	var last *api.URLStatsResponse
+	for {
+		resp, err := getURLStats(id)
+		switch {
+		case ctx.Err() != nil:
+			if inPlace && last != nil {
+				fmt.Fprintln(out)
+			}
+			return nil
+		case err != nil && (last == nil || errors.Is(err, api.ErrNotFound)):
+			return err
+		case err != nil:
+			// A failed poll is tried again at the next one rather than ending
+			// the watch.
+			logging.Warnf("%v", err)
+		default:
+			if last == nil && inPlace {
+				fmt.Fprintln(out, theme.Title.Render(fmt.Sprintf("Watching %s (Ctrl-C to stop)", resp.ShortURL)))
+			}
+			snap := statsSnapshot{Time: time.Now().Format(time.RFC3339), URLStatsResponse: resp}
+			if last != nil {
+				snap.Delta = resp.Clicks - last.Clicks
+			}
+			if err := printStatsSnapshot(cmd, snap, tmpl, inPlace); err != nil {
+				return err
+			}
+			last = resp
+		}
+
+		select {
+		case <-ctx.Done():
+			if inPlace && last != nil {
+				fmt.Fprintln(out)
+			}
+			return nil
+		case <-after(interval):
+		}
+	}
+}
+
+// printStatsSnapshot prints one poll of stats --watch, replacing the
+// previous line when inPlace is set.
+func printStatsSnapshot(cmd *cobra.Command, snap statsSnapshot, tmpl *template.Template, inPlace bool) error {
+	out := cmd.OutOrStdout()
+	switch {
+	case jsonOutput():
+		return printJSONLines(cmd, []statsSnapshot{snap})
+	case tmpl != nil:
+		return printTemplate(cmd, tmpl, snap)
+	case quietOutput():
+		fmt.Fprintln(out, snap.Clicks)
+		return nil
+	}
+
+	line := theme.FormatKeyValue("Clicks", fmt.Sprintf("%d (%+d)", snap.Clicks, snap.Delta)) +
+		theme.FormatKeyValue("Updated", time.Now().Format("15:04:05"))
+	if inPlace {
+		// Clear the rest of the line in case the new one is shorter.
+		fmt.Fprintf(out, "\r%s\033[K", line)
+	} else {
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
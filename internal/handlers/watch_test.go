@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/watzon/0x45-cli/internal/client"
+	api "github.com/watzon/0x45-cli/pkg/client"
+)
+
+func TestStatsWatch(t *testing.T) {
+	var clicks int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clicks += 2
+		_ = json.NewEncoder(w).Encode(api.URLStatsResponse{Success: true, Id: "abc123", ShortURL: "https://0x45.st/abc123", Clicks: clicks})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	viper.Set("api_key", "test-key")
+	client.Initialize()
+
+	oldAfter := after
+	defer func() { after = oldAfter }()
+
+	watch := func() string {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// The third wait is interrupted, as if by Ctrl-C.
+		waits := 0
+		after = func(time.Duration) <-chan time.Time {
+			waits++
+			ch := make(chan time.Time, 1)
+			if waits == 3 {
+				cancel()
+				return ch
+			}
+			ch <- time.Now()
+			return ch
+		}
+
+		cmd := NewStatsCmd()
+		cmd.SetContext(ctx)
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		_ = cmd.Flags().Set("watch", "true")
+		if err := Stats(cmd, []string{"abc123"}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	clicks = 0
+	output := watch()
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "(+0)") || !strings.Contains(lines[2], "6 (+2)") {
+		t.Errorf("Expected a line per poll with the click delta, got:\n%s", output)
+	}
+
+	viper.Set("json", true)
+	defer viper.Set("json", false)
+	clicks = 0
+	var snaps []statsSnapshot
+	dec := json.NewDecoder(strings.NewReader(watch()))
+	for dec.More() {
+		var snap statsSnapshot
+		if err := dec.Decode(&snap); err != nil {
+			t.Fatal(err)
+		}
+		snaps = append(snaps, snap)
+	}
+	if len(snaps) != 3 || snaps[2].Clicks != 6 || snaps[2].Delta != 2 || snaps[0].Delta != 0 || snaps[0].Time == "" {
+		t.Errorf("Expected a JSON snapshot per poll, got %+v", snaps)
+	}
+
+	cmd := NewStatsCmd()
+	_ = cmd.Flags().Set("interval", "10s")
+	var usageErr *UsageError
+	if err := Stats(cmd, []string{"abc123"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected --interval without --watch to be a usage error, got %v", err)
+	}
+	_ = cmd.Flags().Set("watch", "true")
+	_ = cmd.Flags().Set("interval", "10ms")
+	if err := Stats(cmd, []string{"abc123"}); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a too-short interval to be a usage error, got %v", err)
+	}
+}
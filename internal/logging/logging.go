@@ -0,0 +1,108 @@
+// Package logging is a small leveled logger for diagnostics, written to
+// stderr so it never mixes with command output.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/watzon/0x45-cli/internal/theme"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < Debug || l > Error {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name as used by log_level and --log-level.
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return Warn, nil
+	}
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), nil
+		}
+	}
+	return Warn, fmt.Errorf("invalid log level %q: must be one of %s", name, strings.Join(levelNames, ", "))
+}
+
+var (
+	mu     sync.Mutex
+	level            = Warn
+	output io.Writer = os.Stderr
+)
+
+// SetLevel sets the lowest level that is logged.
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// SetOutput sets where messages are written; stderr by default.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+}
+
+// Enabled reports whether messages at l are logged.
+func Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return l >= level
+}
+
+// Writer returns the log output if messages at l are logged, and nil
+// otherwise, for code that writes its own trace such as the API client.
+func Writer(l Level) io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	if l < level {
+		return nil
+	}
+	return output
+}
+
+func Debugf(format string, args ...any) { logf(Debug, format, args...) }
+func Infof(format string, args ...any)  { logf(Info, format, args...) }
+func Warnf(format string, args ...any)  { logf(Warn, format, args...) }
+func Errorf(format string, args ...any) { logf(Error, format, args...) }
+
+func logf(l Level, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l < level {
+		return
+	}
+
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	switch l {
+	case Warn:
+		msg = theme.FormatWarning(msg)
+	case Error:
+		msg = theme.FormatError(msg)
+	default:
+		msg = l.String() + ": " + msg
+	}
+	fmt.Fprintln(output, msg)
+}
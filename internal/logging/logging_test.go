@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"debug":   Debug,
+		"INFO":    Info,
+		"warn":    Warn,
+		"warning": Warn,
+		" error ": Error,
+	}
+	for name, want := range tests {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseLevel("loud"); err == nil || !strings.Contains(err.Error(), "debug, info, warn, error") {
+		t.Errorf("Expected an error listing the levels, got %v", err)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+	defer SetLevel(Warn)
+
+	SetLevel(Warn)
+	Debugf("request details")
+	Infof("uploading %s", "notes.txt")
+	Warnf("clipboard unavailable")
+	Errorf("something broke")
+	output := buf.String()
+	if strings.Contains(output, "request details") || strings.Contains(output, "notes.txt") {
+		t.Errorf("Expected debug and info to be hidden at warn, got %q", output)
+	}
+	if !strings.Contains(output, "clipboard unavailable") || !strings.Contains(output, "something broke") {
+		t.Errorf("Expected warnings and errors at warn, got %q", output)
+	}
+	if Writer(Debug) != nil {
+		t.Error("Expected no debug writer at warn")
+	}
+
+	buf.Reset()
+	SetLevel(Debug)
+	Debugf("request details\n")
+	Infof("uploading %s", "notes.txt")
+	if buf.String() != "debug: request details\ninfo: uploading notes.txt\n" {
+		t.Errorf("Expected prefixed debug and info lines, got %q", buf.String())
+	}
+	if Writer(Debug) != &buf {
+		t.Error("Expected the output as the debug writer")
+	}
+}
@@ -0,0 +1,12 @@
+package theme
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// DisableColor turns off all ANSI styling, including bold and underline, so
+// output is plain text.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
@@ -0,0 +1,82 @@
+package theme
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hexColor matches the #rgb and #rrggbb colors accepted in the theme config.
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// styleColors maps the names used in the config's theme section to the
+// styles whose foreground color they set, in the order they are listed.
+var styleColors = []struct {
+	name   string
+	styles []*lipgloss.Style
+}{
+	{"title", []*lipgloss.Style{&Title, &TableHeader}},
+	{"subtitle", []*lipgloss.Style{&Subtitle}},
+	{"url", []*lipgloss.Style{&URL}},
+	{"delete_url", []*lipgloss.Style{&DeleteURL}},
+	{"success", []*lipgloss.Style{&Success, &ProgressFilled}},
+	{"warning", []*lipgloss.Style{&Warning}},
+	{"error", []*lipgloss.Style{&Error}},
+	{"key", []*lipgloss.Style{&ListItemKey, &HelpFlag}},
+	{"value", []*lipgloss.Style{&ListItemValue, &TableCell}},
+	{"command", []*lipgloss.Style{&CommandName, &HelpCommand}},
+	{"description", []*lipgloss.Style{&CommandDesc, &HelpDesc}},
+}
+
+// StyleColor is the current color of a configurable style.
+type StyleColor struct {
+	Name  string
+	Color string
+}
+
+// Colors returns the current color of every configurable style.
+func Colors() []StyleColor {
+	colors := make([]StyleColor, 0, len(styleColors))
+	for _, sc := range styleColors {
+		colors = append(colors, StyleColor{Name: sc.name, Color: fmt.Sprint(sc.styles[0].GetForeground())})
+	}
+	return colors
+}
+
+// ApplyColors overrides style colors from the config's theme section, a map
+// of style name to hex color. Unknown names and invalid colors are skipped,
+// keeping the default, and reported in the returned errors.
+func ApplyColors(colors map[string]string) []error {
+	names := make([]string, 0, len(colors))
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := SetColor(name, colors[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// SetColor sets the foreground color of the named style.
+func SetColor(name, color string) error {
+	if !hexColor.MatchString(color) {
+		return fmt.Errorf("invalid color for theme.%s: %q (expected a hex color like #58a6ff)", name, color)
+	}
+	for _, sc := range styleColors {
+		if sc.name != name {
+			continue
+		}
+		for _, style := range sc.styles {
+			*style = style.Foreground(lipgloss.Color(color))
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown theme style: %s", name)
+}
@@ -0,0 +1,41 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyColors(t *testing.T) {
+	title, header, errStyle, success, progress := Title, TableHeader, Error, Success, ProgressFilled
+	defer func() {
+		Title, TableHeader, Error, Success, ProgressFilled = title, header, errStyle, success, progress
+	}()
+
+	errs := ApplyColors(map[string]string{
+		"title":   "#ff00ff",
+		"error":   "red",
+		"success": "#abc",
+		"bogus":   "#000000",
+	})
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "unknown theme style: bogus") || !strings.Contains(errs[1].Error(), "invalid color for theme.error") {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+
+	colors := make(map[string]string)
+	for _, sc := range Colors() {
+		colors[sc.Name] = sc.Color
+	}
+	if colors["title"] != "#ff00ff" || colors["success"] != "#abc" {
+		t.Errorf("Expected valid colors to be applied, got %v", colors)
+	}
+	if colors["error"] != "#f85149" {
+		t.Errorf("Expected invalid color to keep the default, got %s", colors["error"])
+	}
+	if got := TableHeader.GetForeground(); got != Title.GetForeground() {
+		t.Errorf("Expected title color to apply to table headers, got %v", got)
+	}
+}
@@ -92,6 +92,13 @@ var (
 		Bold(true).
 		PaddingRight(2)
 
+	// Progress styles
+	ProgressFilled = lipgloss.NewStyle().
+		Foreground(Teal)
+
+	ProgressEmpty = lipgloss.NewStyle().
+		Foreground(DarkGray)
+
 	// Box styles
 	InfoBox = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
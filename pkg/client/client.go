@@ -0,0 +1,1173 @@
+// Package client is a Go client for the 0x45.st file and URL sharing API.
+//
+// It is the same client the 0x45 command line tool uses and has no
+// dependencies outside the standard library. Create a Client with NewClient
+// and call its methods; every request takes a context for cancellation:
+//
+//	c := client.NewClient("https://0x45.st", apiKey, 0)
+//	resp, err := c.UploadReader(ctx, strings.NewReader("hello"), 5, client.UploadOptions{Filename: "hello.txt"})
+//
+// Non-2xx responses are returned as *APIError, so errors.Is(err, ErrNotFound)
+// identifies missing pastes and URLs. Successful responses that aren't JSON
+// are returned as *UnexpectedResponseError, except that uploads and shortens
+// also accept the bare URL in plain text.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is used when a client is created without a timeout.
+const DefaultTimeout = 30 * time.Second
+
+// Defaults for retrying rate-limited (429) requests.
+const (
+	DefaultMaxRetries   = 2
+	DefaultMaxRetryWait = 10 * time.Second
+)
+
+// Supported values for Client.AuthHeader.
+const (
+	AuthBearer = "bearer"
+	AuthAPIKey = "x-api-key"
+)
+
+// PasswordHeader carries the password of a password-protected paste.
+const PasswordHeader = "X-Paste-Password"
+
+// Supported values for Client.FilenameVia.
+const (
+	FilenameBoth   = "both"
+	FilenameHeader = "header"
+	FilenameQuery  = "query"
+)
+
+// Client talks to a 0x45 server. Its fields may be adjusted after NewClient
+// but not while requests are in flight.
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	Timeout    time.Duration
+	HTTPClient *http.Client
+	// AuthHeader selects how the API key is sent: AuthBearer (the default)
+	// or AuthAPIKey.
+	AuthHeader string
+	// FilenameVia selects how an upload's filename is sent: in the
+	// X-Filename header, the filename query parameter, or both (the
+	// default), since servers differ in which one they read.
+	FilenameVia string
+	// Headers are added to every request, overriding any header the client
+	// would otherwise set, including the API key.
+	Headers http.Header
+	// Logger, when set, receives a trace of every request and response.
+	Logger io.Writer
+	// MaxRetries is how many times a rate-limited request is retried, and
+	// MaxRetryWait the longest Retry-After the client is willing to sleep
+	// for. Anything beyond either limit is returned as an *APIError.
+	MaxRetries   int
+	MaxRetryWait time.Duration
+}
+
+// UploadRequest describes an upload in JSON form.
+type UploadRequest struct {
+	File     string `json:"file"`
+	Private  bool   `json:"private,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// UploadOptions controls how UploadReader sends content.
+type UploadOptions struct {
+	Filename string
+	Private  bool
+	Expires  string
+	// MimeType overrides the content type the server would otherwise detect.
+	MimeType string
+	// Extension, without a dot, hints the syntax highlighting to use.
+	Extension string
+	// Title is a human-friendly name shown alongside the paste.
+	Title string
+	// Password, if set, must be given to view the paste. It is sent in the
+	// PasswordHeader rather than the query string so it stays out of
+	// server logs. Not every server supports it.
+	Password string
+}
+
+// ShortenRequest describes a URL to shorten in JSON form.
+type ShortenRequest struct {
+	URL     string `json:"url"`
+	Private bool   `json:"private,omitempty"`
+	Expires string `json:"expires,omitempty"`
+}
+
+// UploadResponse is the server's reply to an upload.
+type UploadResponse struct {
+	Success   bool   `json:"success"`
+	URL       string `json:"url,omitempty"`
+	DeleteURL string `json:"delete_url,omitempty"`
+	// MimeType is the content type the server stored the upload as.
+	MimeType string `json:"mime_type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ShortenResponse is the server's reply to a shorten request.
+type ShortenResponse struct {
+	Success   bool   `json:"success"`
+	URL       string `json:"url,omitempty"`
+	DeleteURL string `json:"delete_url,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// GenericResponse is the server's reply to requests without a payload, such
+// as deletions.
+type GenericResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ChunkedUploadResponse is the server's reply to the steps of a chunked
+// upload. Offset is how many bytes of the upload the server has stored.
+type ChunkedUploadResponse struct {
+	Success  bool   `json:"success"`
+	UploadID string `json:"upload_id,omitempty"`
+	Offset   int64  `json:"offset"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PasteListItem is one paste in a ListPastes response.
+type PasteListItem struct {
+	Id          string  `json:"id"`
+	Filename    string  `json:"filename"`
+	Title       string  `json:"title,omitempty"`
+	Size        int64   `json:"size"`
+	CreatedAt   string  `json:"created_at"`
+	URL         string  `json:"url"`
+	RawURL      string  `json:"raw_url,omitempty"`
+	DownloadURL string  `json:"download_url,omitempty"`
+	DeleteURL   string  `json:"delete_url,omitempty"`
+	ExpiresAt   *string `json:"expires_at,omitempty"`
+}
+
+// URLListItem is one shortened URL in a ListURLs response.
+type URLListItem struct {
+	Id          string  `json:"id"`
+	URL         string  `json:"url"`
+	ShortURL    string  `json:"short_url"`
+	OriginalURL string  `json:"original_url"`
+	Clicks      int64   `json:"clicks,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	ExpiresAt   *string `json:"expires_at,omitempty"`
+}
+
+// URLStatsResponse holds click statistics for a shortened URL.
+type URLStatsResponse struct {
+	Success     bool    `json:"success"`
+	Id          string  `json:"id,omitempty"`
+	ShortURL    string  `json:"short_url,omitempty"`
+	OriginalURL string  `json:"original_url,omitempty"`
+	Clicks      int64   `json:"clicks"`
+	LastClick   *string `json:"last_click,omitempty"`
+	ExpiresAt   *string `json:"expires_at,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// UpdateExpirationResponse is the server's reply to UpdateURLExpiration.
+type UpdateExpirationResponse struct {
+	Success   bool    `json:"success"`
+	Id        string  `json:"id,omitempty"`
+	ShortURL  string  `json:"short_url,omitempty"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// KeyListItem is one API key in a ListKeys response. Servers may send the
+// key itself, so callers should redact Key before showing it.
+type KeyListItem struct {
+	Id         string  `json:"id"`
+	Name       string  `json:"name,omitempty"`
+	Key        string  `json:"key,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+	LastUsedAt *string `json:"last_used_at,omitempty"`
+	ExpiresAt  *string `json:"expires_at,omitempty"`
+}
+
+// DownloadResponse holds the raw content of a paste. Callers must close Body.
+type DownloadResponse struct {
+	Body     io.ReadCloser
+	Filename string
+	Size     int64
+}
+
+// ListResponse is one page of a list endpoint.
+type ListResponse[T any] struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Items []T `json:"items"`
+		Total int `json:"total,omitempty"`
+		Page  int `json:"page,omitempty"`
+		Limit int `json:"limit,omitempty"`
+	} `json:"data"`
+	Error string `json:"error,omitempty"`
+}
+
+// ListOptions controls pagination and ordering for list requests.
+type ListOptions struct {
+	Page int
+	// PerPage is the page size; 0 leaves it to the server.
+	PerPage int
+	// Order is "asc" or "desc"; empty leaves it to the server.
+	Order string
+	// Sort is the field to sort by, e.g. "created_at"; empty leaves it to
+	// the server.
+	Sort string
+}
+
+func (o ListOptions) values() url.Values {
+	params := url.Values{}
+	params.Set("page", fmt.Sprintf("%d", o.Page))
+	if o.PerPage > 0 {
+		params.Set("per_page", fmt.Sprintf("%d", o.PerPage))
+	}
+	if o.Order != "" {
+		params.Set("order", o.Order)
+	}
+	if o.Sort != "" {
+		params.Set("sort", o.Sort)
+	}
+	return params
+}
+
+// ErrNotFound matches any APIError with a 404 Not Found status.
+var ErrNotFound = errors.New("not found")
+
+// ErrTimeout is returned, wrapped, when a request exceeds the client timeout.
+var ErrTimeout = errors.New("request timed out")
+
+// ErrChunkedUnsupported is returned, wrapped, by StartChunkedUpload when the
+// server has no chunked upload endpoint.
+var ErrChunkedUnsupported = errors.New("server does not support chunked uploads")
+
+// ErrReplaceUnsupported is returned, wrapped, by ReplacePaste when the server
+// can't update a paste in place.
+var ErrReplaceUnsupported = errors.New("server does not support replacing pastes")
+
+// maxErrorBody limits how much of an error response is kept in APIError.
+const maxErrorBody = 4096
+
+// APIError is returned when the server responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	// RetryAfter is the server's requested wait for 429 responses, or zero
+	// if it didn't send one.
+	RetryAfter time.Duration
+	// Location is where a redirect points, which is only returned as an
+	// error when the HTTP client doesn't follow redirects.
+	Location string
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == http.StatusTooManyRequests {
+		if e.RetryAfter <= 0 {
+			return "rate limited, try again later"
+		}
+		return fmt.Sprintf("rate limited, retry in %ds", int(math.Ceil(e.RetryAfter.Seconds())))
+	}
+	if e.Location != "" {
+		return fmt.Sprintf("redirected to %s (status code %d)", e.Location, e.StatusCode)
+	}
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d: %s", e.StatusCode, e.Body)
+}
+
+// Is lets errors.Is(err, ErrNotFound) match 404 responses.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// UnexpectedResponseError is returned when a successful response isn't in a
+// format the client understands, such as an HTML page from a proxy.
+type UnexpectedResponseError struct {
+	// ContentType is the media type the server declared, if any.
+	ContentType string
+	// Body is the start of the response, up to 4 KiB.
+	Body string
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	if e.Body == "" {
+		return "error decoding response: empty response"
+	}
+	contentType := e.ContentType
+	if contentType == "" {
+		contentType = "non-JSON"
+	}
+	return fmt.Sprintf("error decoding response: unexpected %s response: %s", contentType, e.Body)
+}
+
+// ResponseError is returned when the server answers with a 2xx status but
+// reports a failure in the body, as "success": false and an error message.
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return "request failed without an error message"
+	}
+	return e.Message
+}
+
+// decodeResponse reads a successful response into v. JSON is expected, but
+// some servers answer uploads and shortens with just the URL as plain text;
+// fromText, when set, gets the chance to fill v from such a body and
+// reports whether it could. Anything else is an *UnexpectedResponseError.
+// A JSON body with "success": false is a *ResponseError, whatever the
+// status code.
+func decodeResponse(resp *http.Response, v any, fromText func(text string) bool) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Servers don't always label JSON correctly (Go's own sniffing calls it
+	// text/plain), so the body is checked too.
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	text := strings.TrimSpace(string(body))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		if err := json.Unmarshal(body, v); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		// A 200 doesn't always mean the request worked.
+		var result struct {
+			Success *bool  `json:"success"`
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &result) == nil && result.Success != nil && !*result.Success {
+			msg := result.Error
+			if msg == "" {
+				msg = result.Message
+			}
+			return &ResponseError{StatusCode: resp.StatusCode, Message: msg}
+		}
+		return nil
+	}
+
+	if fromText != nil && fromText(text) {
+		return nil
+	}
+	if len(text) > maxErrorBody {
+		text = text[:maxErrorBody]
+	}
+	return &UnexpectedResponseError{ContentType: mediaType, Body: text}
+}
+
+// plainURL returns text if it is a single absolute http(s) URL.
+func plainURL(text string) (string, bool) {
+	if text == "" || strings.ContainsAny(text, " \t\r\n") {
+		return "", false
+	}
+	u, err := url.Parse(text)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return text, true
+}
+
+// NewClient returns a client for the server at baseURL. apiKey may be empty
+// for anonymous use, and a zero timeout means DefaultTimeout.
+//
+// The timeout bounds connecting to the server and waiting for it to answer,
+// and the whole of requests that only exchange metadata. Uploads and
+// downloads may take as long as their content takes to send, as long as
+// the connection doesn't stall.
+func NewClient(baseURL, apiKey string, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Client{
+		BaseURL:      baseURL,
+		APIKey:       apiKey,
+		Timeout:      timeout,
+		HTTPClient:   &http.Client{Transport: NewTransport(timeout)},
+		AuthHeader:   AuthBearer,
+		FilenameVia:  FilenameBoth,
+		MaxRetries:   DefaultMaxRetries,
+		MaxRetryWait: DefaultMaxRetryWait,
+	}
+}
+
+// NewTransport returns an HTTP transport that gives up on connecting, on the
+// TLS handshake and on waiting for response headers after timeout. Unlike
+// http.Client.Timeout, it puts no limit on how long a request or response
+// body may take to stream, so large or rate-limited transfers aren't cut
+// off partway through.
+func NewTransport(timeout time.Duration) *http.Transport {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	return transport
+}
+
+// withTimeout bounds a request that only exchanges metadata by the client
+// timeout from start to finish. Requests carrying content rely on the
+// transport's timeouts instead.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, c.Timeout, fmt.Errorf("%w after %s", ErrTimeout, c.Timeout))
+}
+
+// doRequest authenticates and sends req, translating timeouts into a
+// readable error and non-2xx responses into an *APIError. Rate-limited
+// requests are retried after the server's Retry-After while within the
+// client's retry budget and the request body can be replayed.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	return c.doRequestWith(c.HTTPClient, req)
+}
+
+// doRequestWith is doRequest using httpClient in place of c.HTTPClient.
+func (c *Client) doRequestWith(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	if c.APIKey != "" {
+		if strings.EqualFold(c.AuthHeader, AuthAPIKey) {
+			req.Header.Set("X-API-Key", c.APIKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.APIKey)
+		}
+	}
+	for name, values := range c.Headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	c.logRequest(req)
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			if ctxErr := contextErr(req.Context()); ctxErr != nil {
+				return nil, ctxErr
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, fmt.Errorf("%w after %s", ErrTimeout, c.Timeout)
+			}
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+
+		c.logf("< %s (%s)\n", resp.Status, time.Since(start).Round(time.Millisecond))
+		if location := resp.Header.Get("Location"); location != "" {
+			c.logf("< Location: %s\n", location)
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return resp, nil
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(body)),
+			Location:   resp.Header.Get("Location"),
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return nil, apiErr
+		}
+
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		if attempt >= c.MaxRetries || apiErr.RetryAfter > c.MaxRetryWait || !rewindBody(req) {
+			return nil, apiErr
+		}
+		c.logf("rate limited, retrying in %s\n", apiErr.RetryAfter)
+		timer := time.NewTimer(apiErr.RetryAfter)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, contextErr(req.Context())
+		case <-timer.C:
+		}
+	}
+}
+
+// contextErr returns why ctx is done, or nil if it isn't. Running out the
+// deadline set by withTimeout is reported as ErrTimeout, like a timeout in
+// the transport.
+func contextErr(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, ErrTimeout) {
+		return cause
+	}
+	return ctx.Err()
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date. Missing or malformed values yield zero.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// rewindBody prepares req to be sent again, reporting false if its body has
+// already been consumed and can't be recreated.
+func rewindBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.Logger != nil {
+		fmt.Fprintf(c.Logger, format, args...)
+	}
+}
+
+func (c *Client) logRequest(req *http.Request) {
+	if c.Logger == nil {
+		return
+	}
+
+	c.logf("> %s %s\n", req.Method, req.URL)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(req.Header.Values(name), ", ")
+		switch name {
+		case "X-Api-Key", PasswordHeader:
+			value = redact(value)
+		case "Authorization":
+			if scheme, credentials, ok := strings.Cut(value, " "); ok {
+				value = scheme + " " + redact(credentials)
+			} else {
+				value = redact(value)
+			}
+		}
+		c.logf("> %s: %s\n", name, value)
+	}
+}
+
+// redact hides all but the last four characters of a secret.
+func redact(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
+
+// Upload uploads the file at filePath under its base name.
+func (c *Client) Upload(ctx context.Context, filePath string, private bool, expires string) (*UploadResponse, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error getting file info: %w", err)
+	}
+
+	return c.UploadReader(ctx, file, fileInfo.Size(), UploadOptions{
+		Filename: filepath.Base(filePath),
+		Private:  private,
+		Expires:  expires,
+	})
+}
+
+// UploadReader uploads the contents of body. A negative size sends the body
+// without a Content-Length.
+func (c *Client) UploadReader(ctx context.Context, body io.Reader, size int64, opts UploadOptions) (*UploadResponse, error) {
+	params, sendHeader := c.uploadParams(opts)
+	reqURL := fmt.Sprintf("%s/upload?%s", c.BaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	setUploadHeaders(req, opts, sendHeader)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result UploadResponse
+	err = decodeResponse(resp, &result, func(text string) bool {
+		u, ok := plainURL(text)
+		result = UploadResponse{Success: ok, URL: u}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// uploadParams returns the query parameters describing an upload, and
+// whether its filename also belongs in the X-Filename header.
+func (c *Client) uploadParams(opts UploadOptions) (url.Values, bool) {
+	params := url.Values{}
+	if opts.Private {
+		params.Set("private", "true")
+	}
+	if opts.Expires != "" {
+		params.Set("expires", opts.Expires)
+	}
+	if opts.MimeType != "" {
+		params.Set("mime", opts.MimeType)
+	}
+	if opts.Extension != "" {
+		params.Set("ext", opts.Extension)
+	}
+	if opts.Title != "" {
+		params.Set("title", opts.Title)
+	}
+	sendHeader, sendQuery := true, true
+	switch strings.ToLower(c.FilenameVia) {
+	case FilenameHeader:
+		sendQuery = false
+	case FilenameQuery:
+		sendHeader = false
+	}
+	if opts.Filename != "" && sendQuery {
+		params.Set("filename", opts.Filename)
+	}
+	return params, sendHeader
+}
+
+// setUploadHeaders sets the content type, filename and password of an
+// upload on req.
+func setUploadHeaders(req *http.Request, opts UploadOptions, sendFilename bool) {
+	contentType := "application/octet-stream"
+	if opts.MimeType != "" {
+		contentType = opts.MimeType
+	}
+	req.Header.Set("Content-Type", contentType)
+	if opts.Filename != "" && sendFilename {
+		req.Header.Set("X-Filename", opts.Filename)
+	}
+	if opts.Password != "" {
+		req.Header.Set(PasswordHeader, opts.Password)
+	}
+}
+
+// ReplacePaste uploads body as the new content of the existing paste id,
+// keeping its ID and URL. opts describe the new content as they would for
+// UploadReader. Servers that can't update pastes in place yield an error
+// matching ErrReplaceUnsupported; since they usually refuse before reading
+// the body, it is only sent once the server agrees to take it.
+func (c *Client) ReplacePaste(ctx context.Context, id string, body io.Reader, size int64, opts UploadOptions) (*UploadResponse, error) {
+	params, sendHeader := c.uploadParams(opts)
+	reqURL := fmt.Sprintf("%s/%s?%s", c.BaseURL, url.PathEscape(id), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "PUT", reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	if size != 0 {
+		req.Header.Set("Expect", "100-continue")
+	}
+	setUploadHeaders(req, opts, sendHeader)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusMethodNotAllowed || apiErr.StatusCode == http.StatusNotImplemented) {
+			return nil, fmt.Errorf("%w (%s)", ErrReplaceUnsupported, apiErr.Status)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result UploadResponse
+	err = decodeResponse(resp, &result, func(text string) bool {
+		u, ok := plainURL(text)
+		result = UploadResponse{Success: ok, URL: u}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Shorten creates a short URL pointing at targetURL.
+func (c *Client) Shorten(ctx context.Context, targetURL string, private bool, expires string) (*ShortenResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	params := url.Values{}
+	if private {
+		params.Set("private", "true")
+	}
+	if expires != "" {
+		params.Set("expires", expires)
+	}
+
+	reqURL := fmt.Sprintf("%s/shorten?%s", c.BaseURL, params.Encode())
+	body := strings.NewReader(targetURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ShortenResponse
+	err = decodeResponse(resp, &result, func(text string) bool {
+		u, ok := plainURL(text)
+		result = ShortenResponse{Success: ok, URL: u}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Delete removes the paste or short URL with the given ID.
+func (c *Client) Delete(ctx context.Context, id string) (*GenericResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/delete/%s", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result GenericResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListPastes returns a page of the pastes owned by the API key.
+func (c *Client) ListPastes(ctx context.Context, opts ListOptions) (*ListResponse[PasteListItem], error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	params := opts.values()
+
+	reqURL := fmt.Sprintf("%s/pastes?%s", c.BaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ListResponse[PasteListItem]
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListURLs returns a page of the short URLs owned by the API key.
+func (c *Client) ListURLs(ctx context.Context, opts ListOptions) (*ListResponse[URLListItem], error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	params := opts.values()
+
+	reqURL := fmt.Sprintf("%s/urls?%s", c.BaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ListResponse[URLListItem]
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListKeys returns the API keys of the account the API key belongs to.
+func (c *Client) ListKeys(ctx context.Context) (*ListResponse[KeyListItem], error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/keys", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ListResponse[KeyListItem]
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// RevokeKey invalidates one of the account's API keys by ID.
+func (c *Client) RevokeKey(ctx context.Context, id string) (*GenericResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/keys/%s", c.BaseURL, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, "DELETE", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result GenericResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetURLStats returns click statistics for a short URL.
+func (c *Client) GetURLStats(ctx context.Context, id string) (*URLStatsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/urls/%s/stats", c.BaseURL, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result URLStatsResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateURLExpiration changes when a short URL expires. expires is a
+// duration such as "24h".
+func (c *Client) UpdateURLExpiration(ctx context.Context, id string, expires string) (*UpdateExpirationResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("expires", expires)
+
+	reqURL := fmt.Sprintf("%s/urls/%s/expiration?%s", c.BaseURL, url.PathEscape(id), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "PUT", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result UpdateExpirationResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Download fetches the raw content of a paste.
+func (c *Client) Download(ctx context.Context, id string) (*DownloadResponse, error) {
+	return c.DownloadWithPassword(ctx, id, "")
+}
+
+// DownloadWithPassword fetches the raw content of a password-protected
+// paste. An empty password behaves like Download.
+func (c *Client) DownloadWithPassword(ctx context.Context, id, password string) (*DownloadResponse, error) {
+	reqURL := fmt.Sprintf("%s/%s/raw", c.BaseURL, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if password != "" {
+		req.Header.Set(PasswordHeader, password)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var filename string
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		filename = filepath.Base(params["filename"])
+	}
+
+	return &DownloadResponse{
+		Body:     resp.Body,
+		Filename: filename,
+		Size:     resp.ContentLength,
+	}, nil
+}
+
+// Exists reports whether a paste or short URL with the given ID exists. With
+// raw set, it checks for the paste's raw content instead, which short URLs
+// don't have. Redirects aren't followed, so a short URL is found without
+// contacting the site it points to.
+func (c *Client) Exists(ctx context.Context, id string, raw bool) (bool, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/%s", c.BaseURL, url.PathEscape(id))
+	if raw {
+		reqURL += "/raw"
+	}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", reqURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating request: %w", err)
+	}
+
+	noFollow := *c.HTTPClient
+	noFollow.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := c.doRequestWith(&noFollow, req)
+	if err != nil {
+		var apiErr *APIError
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return false, nil
+		case errors.As(err, &apiErr) && apiErr.StatusCode >= 300 && apiErr.StatusCode < 400:
+			return true, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+
+	return true, nil
+}
+
+// StartChunkedUpload begins an upload of size bytes that is sent in pieces
+// with UploadChunk and finished with CompleteChunkedUpload. opts describe the
+// paste as they would for UploadReader. Servers without chunked uploads yield
+// an error matching ErrChunkedUnsupported.
+func (c *Client) StartChunkedUpload(ctx context.Context, size int64, opts UploadOptions) (*ChunkedUploadResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	params, sendHeader := c.uploadParams(opts)
+	reqURL := fmt.Sprintf("%s/uploads?%s", c.BaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	setUploadHeaders(req, opts, sendHeader)
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.StatusCode {
+			case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+				return nil, fmt.Errorf("%w (%s)", ErrChunkedUnsupported, apiErr.Status)
+			}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ChunkedUploadResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+	if result.Success && result.UploadID == "" {
+		return nil, fmt.Errorf("%w (no upload ID in response)", ErrChunkedUnsupported)
+	}
+	return &result, nil
+}
+
+// ChunkedUploadStatus reports how much of a chunked upload the server has
+// stored, so an interrupted upload can carry on from there. Uploads the
+// server has forgotten yield an error matching ErrNotFound.
+func (c *Client) ChunkedUploadStatus(ctx context.Context, uploadID string) (*ChunkedUploadResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/uploads/%s", c.BaseURL, url.PathEscape(uploadID))
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ChunkedUploadResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UploadChunk sends chunk as the bytes of a chunked upload starting at
+// offset. The response's Offset is how far the server has now stored, which
+// is where the next chunk starts.
+func (c *Client) UploadChunk(ctx context.Context, uploadID string, offset int64, chunk []byte) (*ChunkedUploadResponse, error) {
+	reqURL := fmt.Sprintf("%s/uploads/%s", c.BaseURL, url.PathEscape(uploadID))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", reqURL, bytes.NewReader(chunk))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ChunkedUploadResponse
+	if err := decodeResponse(resp, &result, nil); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CompleteChunkedUpload finishes a chunked upload once all of its bytes are
+// stored, creating the paste.
+func (c *Client) CompleteChunkedUpload(ctx context.Context, uploadID string) (*UploadResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/uploads/%s/complete", c.BaseURL, url.PathEscape(uploadID))
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result UploadResponse
+	err = decodeResponse(resp, &result, func(text string) bool {
+		u, ok := plainURL(text)
+		result = UploadResponse{Success: ok, URL: u}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
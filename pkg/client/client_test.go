@@ -0,0 +1,487 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthHeader(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	tmpfile, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	methods := map[string]func(c *Client) error{
+		"Upload": func(c *Client) error {
+			_, err := c.Upload(context.Background(), tmpfile.Name(), false, "")
+			return err
+		},
+		"UploadReader": func(c *Client) error {
+			_, err := c.UploadReader(context.Background(), strings.NewReader("test"), 4, UploadOptions{Filename: "test.txt"})
+			return err
+		},
+		"Shorten": func(c *Client) error {
+			_, err := c.Shorten(context.Background(), "https://example.com", false, "")
+			return err
+		},
+		"Delete": func(c *Client) error {
+			_, err := c.Delete(context.Background(), "abc123")
+			return err
+		},
+		"ListPastes": func(c *Client) error {
+			_, err := c.ListPastes(context.Background(), ListOptions{Page: 1, PerPage: 10})
+			return err
+		},
+		"ListURLs": func(c *Client) error {
+			_, err := c.ListURLs(context.Background(), ListOptions{Page: 1, PerPage: 10})
+			return err
+		},
+		"GetURLStats": func(c *Client) error {
+			_, err := c.GetURLStats(context.Background(), "abc123")
+			return err
+		},
+		"UpdateURLExpiration": func(c *Client) error {
+			_, err := c.UpdateURLExpiration(context.Background(), "abc123", "24h")
+			return err
+		},
+		"Download": func(c *Client) error {
+			resp, err := c.Download(context.Background(), "abc123")
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		},
+		"Exists": func(c *Client) error {
+			_, err := c.Exists(context.Background(), "abc123", false)
+			return err
+		},
+	}
+
+	for name, call := range methods {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(server.URL, "test-key", 0)
+			if err := call(c); err != nil {
+				t.Fatal(err)
+			}
+			if auth := got.Get("Authorization"); auth != "Bearer test-key" {
+				t.Errorf("Expected Authorization header to be 'Bearer test-key', got %q", auth)
+			}
+			if key := got.Get("X-API-Key"); key != "" {
+				t.Errorf("Expected no X-API-Key header, got %q", key)
+			}
+
+			c.AuthHeader = AuthAPIKey
+			if err := call(c); err != nil {
+				t.Fatal(err)
+			}
+			if key := got.Get("X-API-Key"); key != "test-key" {
+				t.Errorf("Expected X-API-Key header to be test-key, got %q", key)
+			}
+			if auth := got.Get("Authorization"); auth != "" {
+				t.Errorf("Expected no Authorization header, got %q", auth)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/urls/missing/stats":
+			http.Error(w, "no such URL", http.StatusNotFound)
+		default:
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "test-key", 0)
+
+	_, err := c.Delete(context.Background(), "abc123")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Body != "invalid API key" {
+		t.Errorf("Unexpected APIError fields: %+v", apiErr)
+	}
+	if apiErr.Error() != "unexpected status code: 401: invalid API key" {
+		t.Errorf("Unexpected error string: %s", apiErr.Error())
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("Expected 401 not to match ErrNotFound")
+	}
+
+	_, err = c.GetURLStats(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected 404 to match ErrNotFound, got %v", err)
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload":
+			_, _ = w.Write([]byte(`{"success": false, "error": "file type not allowed"}`))
+		case "/pastes":
+			_, _ = w.Write([]byte(`{"success": false}`))
+		default:
+			_, _ = w.Write([]byte(`{"success": false, "message": "already deleted"}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "test-key", 0)
+
+	_, err := c.UploadReader(context.Background(), strings.NewReader("test"), 4, UploadOptions{Filename: "test.exe"})
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("Expected *ResponseError for a 200 with success false, got %T: %v", err, err)
+	}
+	if respErr.StatusCode != http.StatusOK || err.Error() != "file type not allowed" {
+		t.Errorf("Expected the server's error message, got %+v", respErr)
+	}
+
+	if _, err := c.Delete(context.Background(), "abc123"); err == nil || err.Error() != "already deleted" {
+		t.Errorf("Expected the message to be used without an error field, got %v", err)
+	}
+	if _, err := c.ListPastes(context.Background(), ListOptions{}); !errors.As(err, &respErr) {
+		t.Errorf("Expected *ResponseError without a message, got %v", err)
+	}
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": true, "url": "https://0x45.st/abc"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "test-key", 0)
+	resp, err := c.Shorten(context.Background(), "https://example.com", false, "")
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if !resp.Success || requests != 2 {
+		t.Errorf("Expected success after 2 requests, got %+v after %d", resp, requests)
+	}
+}
+
+func TestRateLimitExceeded(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "120")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "test-key", 0)
+	_, err := c.Delete(context.Background(), "abc123")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %T: %v", err, err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected no retry beyond MaxRetryWait, got %d requests", requests)
+	}
+	if apiErr.RetryAfter != 120*time.Second {
+		t.Errorf("Expected RetryAfter of 120s, got %s", apiErr.RetryAfter)
+	}
+	if err.Error() != "rate limited, retry in 120s" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+}
+
+func TestResponseContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantURL     string
+		wantErr     bool
+	}{
+		{"json", "application/json", `{"success":true,"url":"https://0x45.st/abc"}`, "https://0x45.st/abc", false},
+		{"unlabelled json", "text/plain; charset=utf-8", `{"success":true,"url":"https://0x45.st/abc"}`, "https://0x45.st/abc", false},
+		{"plain text url", "text/plain", "https://0x45.st/abc\n", "https://0x45.st/abc", false},
+		{"html", "text/html", "<html>Proxy login</html>", "", true},
+		{"empty", "text/plain", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			c := NewClient(server.URL, "", time.Second)
+
+			upload, err := c.UploadReader(context.Background(), strings.NewReader("test"), 4, UploadOptions{Filename: "test.txt"})
+			shorten, shortenErr := c.Shorten(context.Background(), "https://example.com", false, "")
+			if tt.wantErr {
+				var unexpected *UnexpectedResponseError
+				if !errors.As(err, &unexpected) || !errors.As(shortenErr, &unexpected) {
+					t.Fatalf("Expected UnexpectedResponseError, got %v and %v", err, shortenErr)
+				}
+				if unexpected.Body != strings.TrimSpace(tt.body) {
+					t.Errorf("Expected the raw body in the error, got %q", unexpected.Body)
+				}
+				return
+			}
+			if err != nil || shortenErr != nil {
+				t.Fatalf("Unexpected errors: %v, %v", err, shortenErr)
+			}
+			if !upload.Success || upload.URL != tt.wantURL {
+				t.Errorf("Expected upload URL %q, got %+v", tt.wantURL, upload)
+			}
+			if !shorten.Success || shorten.URL != tt.wantURL {
+				t.Errorf("Expected short URL %q, got %+v", tt.wantURL, shorten)
+			}
+		})
+	}
+
+	// Only uploads and shortens accept a bare URL.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("https://0x45.st/abc"))
+	}))
+	defer server.Close()
+	c := NewClient(server.URL, "", time.Second)
+	var unexpected *UnexpectedResponseError
+	if _, err := c.ListPastes(context.Background(), ListOptions{Page: 1}); !errors.As(err, &unexpected) {
+		t.Errorf("Expected UnexpectedResponseError for a plain-text listing, got %v", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	c := NewClient(server.URL, "", time.Minute)
+	start := time.Now()
+	_, err := c.UploadReader(ctx, strings.NewReader("test"), 4, UploadOptions{Filename: "test.txt"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Cancelled request took %s to return", elapsed)
+	}
+}
+
+// slowReader yields one byte per delay.
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestTimeoutLeavesTransfersAlone(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload":
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`{"success": true, "url": "https://0x45.st/` + string(body) + `"}`))
+		case "/abc123/raw":
+			w.WriteHeader(http.StatusOK)
+			for _, b := range []byte("slow") {
+				time.Sleep(timeout / 2)
+				_, _ = w.Write([]byte{b})
+				w.(http.Flusher).Flush()
+			}
+		case "/pastes":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(3 * timeout):
+			}
+		case "/shorten":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(3 * timeout):
+			}
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "", timeout)
+
+	// Transfers that take longer than the timeout but never stall succeed.
+	resp, err := c.UploadReader(context.Background(), &slowReader{data: []byte("slow"), delay: timeout / 2}, 4, UploadOptions{})
+	if err != nil || resp.URL != "https://0x45.st/slow" {
+		t.Errorf("Expected a slow upload to succeed, got %+v, %v", resp, err)
+	}
+	download, err := c.Download(context.Background(), "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(download.Body)
+	download.Body.Close()
+	if err != nil || string(body) != "slow" {
+		t.Errorf("Expected a slow download to succeed, got %q, %v", body, err)
+	}
+
+	// A server that never answers, or answers a metadata request too
+	// slowly, still times out.
+	if _, err := c.Shorten(context.Background(), "https://example.com", false, ""); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected no response to time out, got %v", err)
+	}
+	if _, err := c.ListPastes(context.Background(), ListOptions{}); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a stalled listing to time out, got %v", err)
+	}
+}
+
+func TestUploadStreamsFile(t *testing.T) {
+	const size = 64 << 20
+
+	var received, contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		received, _ = io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"success":true,"url":"https://0x45.st/abc123"}`))
+	}))
+	defer server.Close()
+
+	// A sparse file reads back as zeros without taking up disk space.
+	name := filepath.Join(t.TempDir(), "large.bin")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	c := NewClient(server.URL, "", time.Minute)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	if _, err := c.Upload(context.Background(), name, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if contentLength != size || received != size {
+		t.Errorf("Expected %d bytes with a Content-Length, got %d (Content-Length %d)", size, received, contentLength)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/4 {
+		t.Errorf("Upload allocated %d bytes for a %d byte file; the file should be streamed", allocated, size)
+	}
+
+	// Content of unknown length is sent chunked rather than buffered.
+	if _, err := c.UploadReader(context.Background(), io.LimitReader(zeroReader{}, 1<<20), -1, UploadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if contentLength != -1 || received != 1<<20 {
+		t.Errorf("Expected a chunked upload of %d bytes, got %d (Content-Length %d)", 1<<20, received, contentLength)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestChunkedUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/uploads":
+			if r.Header.Get("Upload-Length") != "5" || r.URL.Query().Get("private") != "true" {
+				t.Errorf("Unexpected start request: %s %v", r.URL, r.Header)
+			}
+			_, _ = w.Write([]byte(`{"success": true, "upload_id": "up1"}`))
+		case r.Method == "PATCH" && r.URL.Path == "/uploads/up1":
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("Upload-Offset") != "2" || string(body) != "llo" {
+				t.Errorf("Unexpected chunk at %s: %q", r.Header.Get("Upload-Offset"), body)
+			}
+			_, _ = w.Write([]byte(`{"success": true, "offset": 5}`))
+		case r.Method == "POST" && r.URL.Path == "/uploads/up1/complete":
+			_, _ = w.Write([]byte("https://0x45.st/abc\n"))
+		default:
+			http.Error(w, "no such endpoint", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "test-key", 0)
+	ctx := context.Background()
+	start, err := c.StartChunkedUpload(ctx, 5, UploadOptions{Filename: "hello.txt", Private: true})
+	if err != nil || start.UploadID != "up1" {
+		t.Fatalf("Expected upload up1, got %+v (%v)", start, err)
+	}
+	chunk, err := c.UploadChunk(ctx, "up1", 2, []byte("llo"))
+	if err != nil || chunk.Offset != 5 {
+		t.Fatalf("Expected offset 5, got %+v (%v)", chunk, err)
+	}
+	done, err := c.CompleteChunkedUpload(ctx, "up1")
+	if err != nil || done.URL != "https://0x45.st/abc" {
+		t.Fatalf("Expected the paste URL, got %+v (%v)", done, err)
+	}
+
+	_, err = c.ChunkedUploadStatus(ctx, "gone")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a forgotten upload to match ErrNotFound, got %v", err)
+	}
+
+	unsupported := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}))
+	defer unsupported.Close()
+
+	c = NewClient(unsupported.URL, "", 0)
+	if _, err := c.StartChunkedUpload(ctx, 5, UploadOptions{}); !errors.Is(err, ErrChunkedUnsupported) {
+		t.Errorf("Expected ErrChunkedUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/watzon/0x45-cli/pkg/client"
+)
+
+func ExampleClient_UploadReader() {
+	c := client.NewClient("https://0x45.st", "YOUR_API_KEY", 0)
+
+	content := "hello, world\n"
+	resp, err := c.UploadReader(context.Background(), strings.NewReader(content), int64(len(content)), client.UploadOptions{
+		Filename: "hello.txt",
+		Expires:  "24h",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp.URL)
+}
+
+func ExampleClient_ListPastes() {
+	c := client.NewClient("https://0x45.st", "YOUR_API_KEY", 0)
+
+	resp, err := c.ListPastes(context.Background(), client.ListOptions{Page: 1, PerPage: 20, Order: "desc"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, paste := range resp.Data.Items {
+		fmt.Println(paste.Id, paste.Filename)
+	}
+}
+
+func ExampleAPIError() {
+	c := client.NewClient("https://0x45.st", "YOUR_API_KEY", 0)
+
+	_, err := c.GetURLStats(context.Background(), "missing")
+	var apiErr *client.APIError
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		fmt.Println("no such URL")
+	case errors.As(err, &apiErr):
+		fmt.Println("server returned", apiErr.StatusCode)
+	case err != nil:
+		log.Fatal(err)
+	}
+}